@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	// alarmNamePrefix marks the alarms this tool manages; cleanup only deletes alarms with it
+	alarmNamePrefix = "awsservicesquotafetcher-"
+	alarmPeriod     = 300
+	// alarmDeleteBatch is the most alarm names DeleteAlarms accepts per request
+	alarmDeleteBatch = 100
+)
+
+type cloudWatchAlarmAPI interface {
+	PutMetricAlarm(ctx context.Context, params *cloudwatch.PutMetricAlarmInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricAlarmOutput, error)
+	DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error)
+	DeleteAlarms(ctx context.Context, params *cloudwatch.DeleteAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteAlarmsOutput, error)
+}
+
+// alarmOptions are the settings shared by every generated alarm
+type alarmOptions struct {
+	Threshold   float64 // utilization percentage
+	SNSTopicARN string
+	DryRun      bool
+}
+
+// alarmQuotaCodePattern matches what follows a service's prefix in an alarm name, so the
+// prefix for "ec2" does not also pick up alarms of services like "ec2-instance-connect"
+var alarmQuotaCodePattern = regexp.MustCompile(`^L-[0-9A-Z]+$`)
+
+// alarmServicePrefix starts the names of all alarms for one service
+func alarmServicePrefix(serviceCode string) string {
+	return alarmNamePrefix + strings.ToLower(serviceCode) + "-"
+}
+
+func alarmName(def quotaDefinition) string {
+	return alarmServicePrefix(def.ServiceCode) + def.QuotaCode
+}
+
+// quotaAlarm defines an alarm on usage as a percentage of the quota, using SERVICE_QUOTA() metric math
+func quotaAlarm(def quotaDefinition, opts alarmOptions) *cloudwatch.PutMetricAlarmInput {
+	m := def.UsageMetric
+	keys := make([]string, 0, len(m.Dimensions))
+	for k := range m.Dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	dimensions := make([]cwtypes.Dimension, 0, len(keys))
+	for _, k := range keys {
+		dimensions = append(dimensions, cwtypes.Dimension{Name: aws.String(k), Value: aws.String(m.Dimensions[k])})
+	}
+	stat := m.Statistic
+	if stat == "" {
+		stat = "Maximum"
+	}
+
+	input := &cloudwatch.PutMetricAlarmInput{
+		AlarmName: aws.String(alarmName(def)),
+		AlarmDescription: aws.String(fmt.Sprintf("%s quota %q (%s) above %.0f%% utilization. Managed by awsservicesquotafetcher.",
+			def.ServiceCode, def.QuotaName, def.QuotaCode, opts.Threshold)),
+		ComparisonOperator: cwtypes.ComparisonOperatorGreaterThanOrEqualToThreshold,
+		EvaluationPeriods:  aws.Int32(1),
+		Threshold:          aws.Float64(opts.Threshold),
+		TreatMissingData:   aws.String("notBreaching"),
+		Metrics: []cwtypes.MetricDataQuery{
+			{
+				Id: aws.String("usage"),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &cwtypes.Metric{
+						Namespace:  aws.String(m.Namespace),
+						MetricName: aws.String(m.Name),
+						Dimensions: dimensions,
+					},
+					Period: aws.Int32(alarmPeriod),
+					Stat:   aws.String(stat),
+				},
+				ReturnData: aws.Bool(false),
+			},
+			{
+				Id:         aws.String("utilization"),
+				Expression: aws.String("(usage / SERVICE_QUOTA(usage)) * 100"),
+				Label:      aws.String("Utilization (%)"),
+				ReturnData: aws.Bool(true),
+			},
+		},
+		Tags: []cwtypes.Tag{
+			{Key: aws.String("ManagedBy"), Value: aws.String("awsservicesquotafetcher")},
+			{Key: aws.String("QuotaCode"), Value: aws.String(def.QuotaCode)},
+		},
+	}
+	if opts.SNSTopicARN != "" {
+		input.AlarmActions = []string{opts.SNSTopicARN}
+		input.OKActions = []string{opts.SNSTopicARN}
+	}
+	return input
+}
+
+// compactJSON drops the null and empty fields SDK inputs are full of, for readable dry runs
+func compactJSON(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	var prune func(interface{}) interface{}
+	prune = func(v interface{}) interface{} {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, child := range t {
+				if child == nil || child == "" {
+					delete(t, k)
+					continue
+				}
+				t[k] = prune(child)
+			}
+		case []interface{}:
+			for i, child := range t {
+				t[i] = prune(child)
+			}
+		}
+		return v
+	}
+	return prune(generic)
+}
+
+// syncQuotaAlarms creates or updates an alarm for every quota with a usage metric
+func syncQuotaAlarms(ctx context.Context, api cloudWatchAlarmAPI, defs []quotaDefinition, opts alarmOptions, out *json.Encoder) (int, error) {
+	count := 0
+	for _, def := range defs {
+		if def.UsageMetric == nil {
+			continue
+		}
+		input := quotaAlarm(def, opts)
+		count++
+		if opts.DryRun {
+			if err := out.Encode(compactJSON(input)); err != nil {
+				return count, err
+			}
+			continue
+		}
+		if _, err := api.PutMetricAlarm(ctx, input); err != nil {
+			return count, fmt.Errorf("error putting alarm %s: %v", aws.ToString(input.AlarmName), err)
+		}
+	}
+	return count, nil
+}
+
+// cleanupQuotaAlarms deletes the alarms this tool created, optionally limited to some services
+func cleanupQuotaAlarms(ctx context.Context, api cloudWatchAlarmAPI, services []string, dryRun bool) ([]string, error) {
+	prefixes := []string{alarmNamePrefix}
+	if len(services) > 0 {
+		prefixes = nil
+		for _, service := range services {
+			prefixes = append(prefixes, alarmServicePrefix(service))
+		}
+	}
+
+	var names []string
+	for _, prefix := range prefixes {
+		paginator := cloudwatch.NewDescribeAlarmsPaginator(api, &cloudwatch.DescribeAlarmsInput{
+			AlarmNamePrefix: aws.String(prefix),
+			AlarmTypes:      []cwtypes.AlarmType{cwtypes.AlarmTypeMetricAlarm},
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("error describing alarms: %v", err)
+			}
+			for _, alarm := range page.MetricAlarms {
+				name := aws.ToString(alarm.AlarmName)
+				if len(services) > 0 && !alarmQuotaCodePattern.MatchString(strings.TrimPrefix(name, prefix)) {
+					continue
+				}
+				names = append(names, name)
+			}
+		}
+	}
+	if dryRun {
+		return names, nil
+	}
+
+	for start := 0; start < len(names); start += alarmDeleteBatch {
+		end := min(start+alarmDeleteBatch, len(names))
+		if _, err := api.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{AlarmNames: names[start:end]}); err != nil {
+			return names[:start], fmt.Errorf("error deleting alarms: %v", err)
+		}
+	}
+	return names, nil
+}
+
+// Create, update or remove usage alarms in every region
+func runAlarms(ctx context.Context, cfg aws.Config, action string, regions []string, services []string, opts alarmOptions, cache *QuotaCache) {
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	for _, region := range regions {
+		cfg.Region = region
+		api := cloudwatch.NewFromConfig(cfg)
+
+		switch action {
+		case "sync":
+			if len(services) == 0 {
+				log.Fatal("❌ Error: alarms sync needs --services (or --services all)")
+			}
+			defs := listAllQuotaDefinitions(ctx, cfg, services, cache)
+			count, err := syncQuotaAlarms(ctx, api, defs, opts, out)
+			if err != nil {
+				log.Fatalf("❌ Error: %v", err)
+			}
+			if opts.DryRun {
+				log.Printf("ℹ️ Would put %d alarms in region: %s", count, region)
+			} else {
+				log.Printf("✅ Put %d alarms in region: %s", count, region)
+				fmt.Printf("Put %d alarms in %s\n", count, region)
+			}
+		case "cleanup":
+			names, err := cleanupQuotaAlarms(ctx, api, services, opts.DryRun)
+			if err != nil {
+				log.Fatalf("❌ Error: %v", err)
+			}
+			for _, name := range names {
+				if opts.DryRun {
+					fmt.Printf("would delete %s (%s)\n", name, region)
+				} else {
+					fmt.Printf("deleted %s (%s)\n", name, region)
+				}
+			}
+			log.Printf("✅ Cleaned up %d alarms in region: %s", len(names), region)
+		}
+	}
+	os.Exit(0)
+}
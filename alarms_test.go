@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// fakeAlarms is an in-memory CloudWatch returning alarms two per page
+type fakeAlarms struct {
+	names    []string
+	put      []*cloudwatch.PutMetricAlarmInput
+	prefixes []string
+	deleted  [][]string
+}
+
+func (f *fakeAlarms) PutMetricAlarm(ctx context.Context, params *cloudwatch.PutMetricAlarmInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricAlarmOutput, error) {
+	f.put = append(f.put, params)
+	return &cloudwatch.PutMetricAlarmOutput{}, nil
+}
+
+func (f *fakeAlarms) DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	prefix := aws.ToString(params.AlarmNamePrefix)
+	if params.NextToken == nil {
+		f.prefixes = append(f.prefixes, prefix)
+	}
+	var matched []string
+	for _, name := range f.names {
+		if strings.HasPrefix(name, prefix) {
+			matched = append(matched, name)
+		}
+	}
+	start, _ := strconv.Atoi(aws.ToString(params.NextToken))
+	end := min(start+2, len(matched))
+	out := &cloudwatch.DescribeAlarmsOutput{}
+	for _, name := range matched[start:end] {
+		out.MetricAlarms = append(out.MetricAlarms, cwtypes.MetricAlarm{AlarmName: aws.String(name)})
+	}
+	if end < len(matched) {
+		out.NextToken = aws.String(strconv.Itoa(end))
+	}
+	return out, nil
+}
+
+func (f *fakeAlarms) DeleteAlarms(ctx context.Context, params *cloudwatch.DeleteAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteAlarmsOutput, error) {
+	f.deleted = append(f.deleted, append([]string(nil), params.AlarmNames...))
+	return &cloudwatch.DeleteAlarmsOutput{}, nil
+}
+
+func testAlarmDefinitions() []quotaDefinition {
+	return []quotaDefinition{
+		{
+			ServiceCode: "lambda",
+			QuotaCode:   "L-B99A9384",
+			QuotaName:   "Concurrent executions",
+			UsageMetric: &usageMetric{
+				Namespace:  "AWS/Usage",
+				Name:       "ResourceCount",
+				Dimensions: map[string]string{"Type": "Resource", "Service": "Lambda", "Resource": "ConcurrentExecutions", "Class": "None"},
+			},
+		},
+		// Quotas without a usage metric cannot be alarmed on
+		{ServiceCode: "lambda", QuotaCode: "L-2ACBD22F", QuotaName: "Function and layer storage"},
+	}
+}
+
+func TestQuotaAlarm(t *testing.T) {
+	def := testAlarmDefinitions()[0]
+	input := quotaAlarm(def, alarmOptions{Threshold: 85, SNSTopicARN: "arn:aws:sns:us-east-1:123456789012:quotas"})
+
+	if got := aws.ToString(input.AlarmName); got != "awsservicesquotafetcher-lambda-L-B99A9384" {
+		t.Errorf("AlarmName = %q", got)
+	}
+	if aws.ToFloat64(input.Threshold) != 85 || input.ComparisonOperator != cwtypes.ComparisonOperatorGreaterThanOrEqualToThreshold {
+		t.Errorf("threshold = %v %s", aws.ToFloat64(input.Threshold), input.ComparisonOperator)
+	}
+	if len(input.Metrics) != 2 {
+		t.Fatalf("got %d metric queries, want 2", len(input.Metrics))
+	}
+	usage, expr := input.Metrics[0], input.Metrics[1]
+	if aws.ToString(usage.Id) != "usage" || aws.ToBool(usage.ReturnData) {
+		t.Errorf("usage query = %+v", usage)
+	}
+	if got := aws.ToString(usage.MetricStat.Stat); got != "Maximum" {
+		t.Errorf("Stat = %q, want the Maximum default", got)
+	}
+	var dims []string
+	for _, d := range usage.MetricStat.Metric.Dimensions {
+		dims = append(dims, aws.ToString(d.Name)+"="+aws.ToString(d.Value))
+	}
+	if want := []string{"Class=None", "Resource=ConcurrentExecutions", "Service=Lambda", "Type=Resource"}; !reflect.DeepEqual(dims, want) {
+		t.Errorf("dimensions = %v, want %v", dims, want)
+	}
+	if got := aws.ToString(expr.Expression); got != "(usage / SERVICE_QUOTA(usage)) * 100" || !aws.ToBool(expr.ReturnData) {
+		t.Errorf("expression = %q, returns %v", got, aws.ToBool(expr.ReturnData))
+	}
+	if len(input.AlarmActions) != 1 || len(input.OKActions) != 1 || input.AlarmActions[0] != "arn:aws:sns:us-east-1:123456789012:quotas" {
+		t.Errorf("actions = %v / %v", input.AlarmActions, input.OKActions)
+	}
+}
+
+func TestSyncQuotaAlarms(t *testing.T) {
+	api := &fakeAlarms{}
+	var out bytes.Buffer
+	count, err := syncQuotaAlarms(context.Background(), api, testAlarmDefinitions(), alarmOptions{Threshold: 80, DryRun: true}, json.NewEncoder(&out))
+	if err != nil || count != 1 {
+		t.Fatalf("dry run = %d, %v", count, err)
+	}
+	if len(api.put) != 0 {
+		t.Errorf("dry run put %d alarms", len(api.put))
+	}
+	var printed map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &printed); err != nil {
+		t.Fatalf("dry run output is not JSON: %v\n%s", err, out.String())
+	}
+	if printed["AlarmName"] != "awsservicesquotafetcher-lambda-L-B99A9384" {
+		t.Errorf("printed AlarmName = %v", printed["AlarmName"])
+	}
+	// Unset fields are pruned from the dry run output
+	if _, ok := printed["ExtendedStatistic"]; ok {
+		t.Errorf("printed null fields: %s", out.String())
+	}
+
+	count, err = syncQuotaAlarms(context.Background(), api, testAlarmDefinitions(), alarmOptions{Threshold: 80}, json.NewEncoder(&out))
+	if err != nil || count != 1 || len(api.put) != 1 {
+		t.Fatalf("sync = %d, %v with %d puts", count, err, len(api.put))
+	}
+}
+
+func TestCleanupQuotaAlarms(t *testing.T) {
+	api := &fakeAlarms{names: []string{
+		"awsservicesquotafetcher-ec2-L-1216C47A",
+		"awsservicesquotafetcher-ec2-L-34B43A08",
+		"awsservicesquotafetcher-ec2-instance-connect-L-11111111",
+		"awsservicesquotafetcher-lambda-L-B99A9384",
+		"someone-elses-alarm",
+	}}
+
+	names, err := cleanupQuotaAlarms(context.Background(), api, []string{"EC2"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"awsservicesquotafetcher-ec2-L-1216C47A", "awsservicesquotafetcher-ec2-L-34B43A08"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ec2 cleanup = %v, want %v", names, want)
+	}
+	if len(api.deleted) != 0 {
+		t.Errorf("dry run deleted %v", api.deleted)
+	}
+	if want := []string{"awsservicesquotafetcher-ec2-"}; !reflect.DeepEqual(api.prefixes, want) {
+		t.Errorf("described prefixes %v, want %v", api.prefixes, want)
+	}
+
+	names, err = cleanupQuotaAlarms(context.Background(), api, nil, true)
+	if err != nil || len(names) != 4 {
+		t.Errorf("cleanup of all services = %v, %v", names, err)
+	}
+}
+
+func TestCleanupQuotaAlarmsBatches(t *testing.T) {
+	api := &fakeAlarms{}
+	for i := 0; i < 2*alarmDeleteBatch+50; i++ {
+		api.names = append(api.names, fmt.Sprintf("awsservicesquotafetcher-ec2-L-%08X", i))
+	}
+	names, err := cleanupQuotaAlarms(context.Background(), api, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != len(api.names) {
+		t.Errorf("deleted %d names, want %d", len(names), len(api.names))
+	}
+	var sizes []int
+	for _, batch := range api.deleted {
+		sizes = append(sizes, len(batch))
+	}
+	if want := []int{alarmDeleteBatch, alarmDeleteBatch, 50}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+}
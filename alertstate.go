@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// AlertKind says how a quota's alert status changed since the previous run
+type AlertKind string
+
+const (
+	AlertNew         AlertKind = "new"
+	AlertEscalated   AlertKind = "escalated"
+	AlertDeescalated AlertKind = "deescalated"
+	AlertRenotify    AlertKind = "renotify"
+	AlertRecovered   AlertKind = "recovered"
+	AlertSustained   AlertKind = "sustained"
+)
+
+// Label is a short human-readable description of the change
+func (k AlertKind) Label() string {
+	switch k {
+	case AlertNew:
+		return "new breach"
+	case AlertEscalated:
+		return "escalated"
+	case AlertDeescalated:
+		return "de-escalated"
+	case AlertRenotify:
+		return "still breaching"
+	case AlertRecovered:
+		return "recovered"
+	case AlertSustained:
+		return "sustained breach"
+	default:
+		return string(k)
+	}
+}
+
+// AlertEvent is a change notifiers should announce
+type AlertEvent struct {
+	Kind     AlertKind
+	Key      string
+	Quota    QuotaInfo
+	Severity Severity
+	Previous Severity
+	Runs     int               // consecutive runs the quota has been breaching
+	Tickets  map[string]string // issue tracker name → ticket opened for this breach
+}
+
+type alertRecord struct {
+	Severity     Severity          `json:"severity"`
+	Since        time.Time         `json:"since"`
+	LastNotified time.Time         `json:"last_notified"`
+	Runs         int               `json:"runs"`
+	Tickets      map[string]string `json:"tickets,omitempty"`
+}
+
+// AlertState remembers which quotas were alerted at which severity, shared by all notifiers
+type AlertState struct {
+	path   string
+	Alerts map[string]*alertRecord `json:"alerts"`
+}
+
+// LoadAlertState reads the state file, starting empty when it does not exist yet
+func LoadAlertState(path string) (*AlertState, error) {
+	state := &AlertState{path: path, Alerts: map[string]*alertRecord{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("error parsing alert state %s: %v", path, err)
+	}
+	if state.Alerts == nil {
+		state.Alerts = map[string]*alertRecord{}
+	}
+	return state, nil
+}
+
+// Evaluate compares the report with the remembered alerts, records the new
+// status and returns what changed. fetched holds every quota fetched this run,
+// before filtering: an alerted quota that was filtered out of the report is
+// recovered once it is back under the thresholds and otherwise keeps its alert,
+// as does one whose fetch failed. A quota breaching for
+// exactly sustainedRuns consecutive runs is reported as sustained (0 disables it).
+func (s *AlertState) Evaluate(report *Report, fetched []QuotaInfo, renotify time.Duration, sustainedRuns int) []AlertEvent {
+	var events []AlertEvent
+	reported := map[string]bool{}
+	for _, q := range report.Quotas {
+		key := quotaKey(report, q)
+		reported[key] = true
+		severity := report.Severity(q)
+		record, known := s.Alerts[key]
+
+		if known && severity != SeverityOK {
+			record.Runs++
+		}
+
+		var kind AlertKind
+		switch {
+		case !known && severity == SeverityOK:
+			continue
+		case !known:
+			kind = AlertNew
+			record = &alertRecord{Since: report.GeneratedAt, Runs: 1}
+			s.Alerts[key] = record
+		case severity == SeverityOK:
+			kind = AlertRecovered
+			delete(s.Alerts, key)
+		case severity > record.Severity:
+			kind = AlertEscalated
+		case severity < record.Severity:
+			kind = AlertDeescalated
+		case sustainedRuns > 0 && record.Runs == sustainedRuns:
+			kind = AlertSustained
+		case renotify > 0 && report.GeneratedAt.Sub(record.LastNotified) >= renotify:
+			kind = AlertRenotify
+		default:
+			continue
+		}
+
+		previous := SeverityOK
+		if known {
+			previous = record.Severity
+		}
+		record.Severity = severity
+		record.LastNotified = report.GeneratedAt
+		events = append(events, AlertEvent{
+			Kind:     kind,
+			Key:      key,
+			Quota:    q,
+			Severity: severity,
+			Previous: previous,
+			Runs:     record.Runs,
+			Tickets:  record.Tickets,
+		})
+	}
+
+	// Filters only change what is shown, so a filtered out quota recovers only when it really has
+	for _, q := range fetched {
+		key := quotaKey(report, q)
+		record, known := s.Alerts[key]
+		if reported[key] || !known || report.Severity(q) != SeverityOK {
+			continue
+		}
+		delete(s.Alerts, key)
+		events = append(events, AlertEvent{
+			Kind:     AlertRecovered,
+			Key:      key,
+			Quota:    q,
+			Severity: SeverityOK,
+			Previous: record.Severity,
+			Tickets:  record.Tickets,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Severity != events[j].Severity {
+			return events[i].Severity > events[j].Severity
+		}
+		return events[i].Quota.UtilizedPerc > events[j].Quota.UtilizedPerc
+	})
+	return events
+}
+
+// SetTicket remembers the ticket a tracker opened for a breaching quota
+func (s *AlertState) SetTicket(key string, tracker string, id string) {
+	record, ok := s.Alerts[key]
+	if !ok {
+		return
+	}
+	if record.Tickets == nil {
+		record.Tickets = map[string]string{}
+	}
+	record.Tickets[tracker] = id
+}
+
+// Save writes the state file
+func (s *AlertState) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0644)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAlertStateEvaluate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadAlertState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	quota := func(perc float64) QuotaInfo {
+		return QuotaInfo{ServiceName: "AWS Lambda", QuotaCode: "L-B99A9384", Region: "us-east-1", Allocated: 100, Used: perc, UtilizedPerc: perc}
+	}
+
+	steps := []struct {
+		perc     float64
+		filtered bool
+		want     []AlertKind
+	}{
+		{50, false, []AlertKind{}},
+		{85, false, []AlertKind{AlertNew}},
+		{86, false, []AlertKind{}},
+		{95, false, []AlertKind{AlertEscalated}},
+		{85, false, []AlertKind{AlertDeescalated}},
+		{85, true, []AlertKind{}},
+		{85, false, []AlertKind{}},
+		{10, true, []AlertKind{AlertRecovered}},
+		{85, false, []AlertKind{AlertNew}},
+		{10, false, []AlertKind{AlertRecovered}},
+	}
+	for i, step := range steps {
+		q := quota(step.perc)
+		report := &Report{AccountID: "123456789012", GeneratedAt: start.Add(time.Duration(i) * time.Hour), Thresholds: Thresholds{Warning: 80, Critical: 90}}
+		if !step.filtered {
+			report.Quotas = []QuotaInfo{q}
+		}
+		events := state.Evaluate(report, []QuotaInfo{q}, 0, 0)
+		if len(events) != len(step.want) {
+			t.Fatalf("step %d: got %d events %+v, want %v", i, len(events), events, step.want)
+		}
+		for j, e := range events {
+			if e.Kind != step.want[j] {
+				t.Errorf("step %d: event %d is %s, want %s", i, j, e.Kind, step.want[j])
+			}
+		}
+	}
+	if len(state.Alerts) != 0 {
+		t.Errorf("alerts left after recovery: %v", state.Alerts)
+	}
+}
+
+func TestAlertStateSaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadAlertState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := QuotaInfo{ServiceName: "Amazon EC2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 10, Used: 10, UtilizedPerc: 100}
+	report := &Report{AccountID: "123456789012", GeneratedAt: time.Now(), Thresholds: Thresholds{Warning: 80, Critical: 90}, Quotas: []QuotaInfo{q}}
+	events := state.Evaluate(report, report.Quotas, 0, 0)
+	state.SetTicket(events[0].Key, "GitHub", "42")
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadAlertState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, ok := loaded.Alerts[events[0].Key]
+	if !ok || record.Severity != SeverityCritical || record.Tickets["GitHub"] != "42" {
+		t.Errorf("reloaded record = %+v", record)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) != 0 {
+		t.Errorf("leftover temp files: %v", matches)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error(err)
+	}
+}
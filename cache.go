@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// QuotaCache stores quota metadata on disk, keyed by account, region and service.
+// Usage is never cached. A nil *QuotaCache disables caching.
+type QuotaCache struct {
+	Dir     string
+	TTL     time.Duration
+	Refresh bool // ignore existing entries but still store fresh ones
+
+	accountOnce sync.Once
+	accountID   string
+	accountErr  error
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewQuotaCache returns a cache rooted at dir
+func NewQuotaCache(dir string, ttl time.Duration, refresh bool) *QuotaCache {
+	return &QuotaCache{Dir: dir, TTL: ttl, Refresh: refresh}
+}
+
+// refreshing returns a cache that skips existing entries but still stores fresh ones; nil stays nil
+func (c *QuotaCache) refreshing() *QuotaCache {
+	if c == nil {
+		return nil
+	}
+	return NewQuotaCache(c.Dir, c.TTL, true)
+}
+
+// defaultCacheDir returns the per-user cache directory for the tool
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "awsservicesquotafetcher")
+}
+
+// Resolve the account ID once per run so entries from different accounts never mix
+func (c *QuotaCache) account(ctx context.Context, cfg aws.Config) (string, error) {
+	c.accountOnce.Do(func() {
+		c.accountID, c.accountErr = lookupAccountID(ctx, cfg)
+	})
+	return c.accountID, c.accountErr
+}
+
+func (c *QuotaCache) path(ctx context.Context, cfg aws.Config, name string) (string, error) {
+	account, err := c.account(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.Dir, account, cfg.Region, name+".json"), nil
+}
+
+// get decodes a fresh cache entry into v and reports whether one was found
+func (c *QuotaCache) get(ctx context.Context, cfg aws.Config, name string, v interface{}) bool {
+	if c == nil || c.Refresh {
+		return false
+	}
+	path, err := c.path(ctx, cfg, name)
+	if err != nil {
+		log.Printf("⚠️ Quota cache disabled: %v", err)
+		return false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Printf("⚠️ Ignoring corrupt cache entry %s: %v", path, err)
+		return false
+	}
+	if time.Since(entry.FetchedAt) > c.TTL {
+		return false
+	}
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		log.Printf("⚠️ Ignoring corrupt cache entry %s: %v", path, err)
+		return false
+	}
+	log.Printf("📦 Using cached %s (fetched %s)", path, entry.FetchedAt.Format(time.RFC3339))
+	return true
+}
+
+// put stores v under name; failures are logged and otherwise ignored
+func (c *QuotaCache) put(ctx context.Context, cfg aws.Config, name string, v interface{}) {
+	if c == nil {
+		return
+	}
+	path, err := c.path(ctx, cfg, name)
+	if err != nil {
+		log.Printf("⚠️ Quota cache disabled: %v", err)
+		return
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("⚠️ Error encoding cache entry %s: %v", path, err)
+		return
+	}
+	entry, err := json.Marshal(cacheEntry{FetchedAt: time.Now(), Data: data})
+	if err != nil {
+		log.Printf("⚠️ Error encoding cache entry %s: %v", path, err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("⚠️ Error creating cache directory: %v", err)
+		return
+	}
+	if err := writeFileAtomic(path, entry, 0644); err != nil {
+		log.Printf("⚠️ Error writing cache entry %s: %v", path, err)
+	}
+}
+
+// writeFileAtomic writes to a unique temp file in the same directory and renames it into
+// place, so concurrent writers (other runs, or serve's goroutines) never expose a partial file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// newTestCache returns a cache whose account is already resolved, so no STS call is made
+func newTestCache(dir string, account string, ttl time.Duration, refresh bool) *QuotaCache {
+	c := NewQuotaCache(dir, ttl, refresh)
+	c.accountOnce.Do(func() { c.accountID = account })
+	return c
+}
+
+func TestQuotaCacheHitAndExpiry(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	cfg := aws.Config{Region: "us-east-1"}
+	c := newTestCache(dir, "123456789012", time.Hour, false)
+
+	var got []string
+	if c.get(ctx, cfg, "ec2", &got) {
+		t.Fatal("empty cache reported a hit")
+	}
+	c.put(ctx, cfg, "ec2", []string{"L-1216C47A"})
+	if !c.get(ctx, cfg, "ec2", &got) || len(got) != 1 || got[0] != "L-1216C47A" {
+		t.Fatalf("fresh entry: hit = %v", got)
+	}
+
+	// Age the entry past the TTL
+	path := filepath.Join(dir, "123456789012", "us-east-1", "ec2.json")
+	stale, _ := json.Marshal(cacheEntry{FetchedAt: time.Now().Add(-2 * time.Hour), Data: json.RawMessage(`["L-OLD"]`)})
+	if err := os.WriteFile(path, stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	if c.get(ctx, cfg, "ec2", &got) {
+		t.Errorf("expired entry reported a hit: %v", got)
+	}
+
+	// Corrupt entries are misses too
+	if err := os.WriteFile(path, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c.get(ctx, cfg, "ec2", &got) {
+		t.Error("corrupt entry reported a hit")
+	}
+}
+
+func TestQuotaCacheRefresh(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	cfg := aws.Config{Region: "us-east-1"}
+	newTestCache(dir, "123456789012", time.Hour, false).put(ctx, cfg, "ec2", []string{"L-OLD"})
+
+	// --refresh-cache skips existing entries but still stores fresh ones
+	refresh := newTestCache(dir, "123456789012", time.Hour, true)
+	var got []string
+	if refresh.get(ctx, cfg, "ec2", &got) {
+		t.Fatal("--refresh-cache read the cache")
+	}
+	refresh.put(ctx, cfg, "ec2", []string{"L-NEW"})
+	if !newTestCache(dir, "123456789012", time.Hour, false).get(ctx, cfg, "ec2", &got) || got[0] != "L-NEW" {
+		t.Errorf("after refresh got %v, want [L-NEW]", got)
+	}
+}
+
+func TestQuotaCacheKeysByAccountAndRegion(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	east, west := aws.Config{Region: "us-east-1"}, aws.Config{Region: "eu-west-1"}
+	a := newTestCache(dir, "111111111111", time.Hour, false)
+	b := newTestCache(dir, "222222222222", time.Hour, false)
+
+	a.put(ctx, east, "ec2", []string{"a-east"})
+	var got []string
+	if a.get(ctx, west, "ec2", &got) {
+		t.Errorf("entry leaked to another region: %v", got)
+	}
+	if b.get(ctx, east, "ec2", &got) {
+		t.Errorf("entry leaked to another account: %v", got)
+	}
+	b.put(ctx, east, "ec2", []string{"b-east"})
+	if !a.get(ctx, east, "ec2", &got) || got[0] != "a-east" {
+		t.Errorf("account a got %v, want [a-east]", got)
+	}
+}
+
+func TestQuotaCacheDisabled(t *testing.T) {
+	ctx := context.Background()
+	cfg := aws.Config{Region: "us-east-1"}
+
+	// --no-cache passes a nil cache around
+	var none *QuotaCache
+	none.put(ctx, cfg, "ec2", []string{"L-1"})
+	var got []string
+	if none.get(ctx, cfg, "ec2", &got) {
+		t.Error("nil cache reported a hit")
+	}
+
+	// Without an account ID the cache turns itself off
+	dir := t.TempDir()
+	c := NewQuotaCache(dir, time.Hour, false)
+	c.accountOnce.Do(func() { c.accountErr = fmt.Errorf("no credentials") })
+	c.put(ctx, cfg, "ec2", []string{"L-1"})
+	if c.get(ctx, cfg, "ec2", &got) {
+		t.Error("cache without an account reported a hit")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("cache without an account wrote %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomicConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entry.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, _ := json.Marshal(map[string]string{"writer": fmt.Sprint(i), "padding": string(make([]byte, 64<<10))})
+			if err := writeFileAtomic(path, data, 0644); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]string
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("entry is corrupt: %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temp files left behind: %d entries", len(entries))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// catalogVersion is bumped whenever the catalog JSON layout changes
+const catalogVersion = 1
+
+// Catalog is an offline snapshot of every quota AWS defines, with defaults per region
+type Catalog struct {
+	Version     int            `json:"version"`
+	ToolVersion string         `json:"tool_version"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Regions     []string       `json:"regions"`
+	Quotas      []catalogQuota `json:"quotas"`
+}
+
+type catalogQuota struct {
+	ServiceCode string             `json:"service_code"`
+	ServiceName string             `json:"service_name"`
+	QuotaCode   string             `json:"quota_code"`
+	QuotaName   string             `json:"quota_name"`
+	Unit        string             `json:"unit,omitempty"`
+	Adjustable  bool               `json:"adjustable"`
+	Global      bool               `json:"global"`
+	UsageMetric *usageMetric       `json:"usage_metric,omitempty"`
+	Defaults    map[string]float64 `json:"defaults"`
+}
+
+func (q catalogQuota) key() string {
+	return q.ServiceCode + "/" + q.QuotaCode
+}
+
+// buildCatalog walks every service in every region and records AWS default values
+func buildCatalog(ctx context.Context, cfg aws.Config, regions []string, cache *QuotaCache) (*Catalog, error) {
+	quotas := map[string]*catalogQuota{}
+	for _, region := range regions {
+		cfg.Region = region
+		services, err := listServices(ctx, cfg, cache)
+		if err != nil {
+			return nil, err
+		}
+		codes := make([]string, 0, len(services))
+		for _, svc := range services {
+			codes = append(codes, svc.Code)
+		}
+
+		log.Printf("📚 Cataloguing %d services in region: %s", len(codes), region)
+		for _, def := range listAllQuotaDefinitions(ctx, cfg, codes, cache) {
+			if def.DefaultValue == nil {
+				continue
+			}
+			entry := catalogQuota{
+				ServiceCode: def.ServiceCode,
+				ServiceName: def.ServiceName,
+				QuotaCode:   def.QuotaCode,
+				QuotaName:   def.QuotaName,
+				Unit:        def.Unit,
+				Adjustable:  def.Adjustable,
+				Global:      def.Global,
+				UsageMetric: def.UsageMetric,
+			}
+			q, ok := quotas[entry.key()]
+			if !ok {
+				entry.Defaults = map[string]float64{}
+				q = &entry
+				quotas[entry.key()] = q
+			}
+			q.Defaults[region] = *def.DefaultValue
+		}
+	}
+
+	catalog := &Catalog{
+		Version:     catalogVersion,
+		ToolVersion: version,
+		GeneratedAt: time.Now().UTC(),
+		Regions:     regions,
+	}
+	for _, q := range quotas {
+		catalog.Quotas = append(catalog.Quotas, *q)
+	}
+	sort.Slice(catalog.Quotas, func(i, j int) bool { return catalog.Quotas[i].key() < catalog.Quotas[j].key() })
+	return catalog, nil
+}
+
+func loadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("error parsing catalog %s: %v", path, err)
+	}
+	if catalog.Version != catalogVersion {
+		return nil, fmt.Errorf("catalog %s has version %d, expected %d", path, catalog.Version, catalogVersion)
+	}
+	return &catalog, nil
+}
+
+// catalogDiff lists quotas added, removed, or whose defaults changed between two catalogs.
+// Only regions both catalogs cover are compared; the others are listed separately.
+type catalogDiff struct {
+	Added   []catalogQuota
+	Removed []catalogQuota
+	Changed []catalogChange
+	OnlyOld []string // regions only the old catalog covers
+	OnlyNew []string // regions only the new catalog covers
+}
+
+type catalogChange struct {
+	Quota  catalogQuota
+	Region string
+	Old    *float64 // nil when the region had no default before
+	New    *float64 // nil when the region no longer has a default
+}
+
+// inRegions reports whether a quota has a default in any of the regions
+func (q catalogQuota) inRegions(regions map[string]bool) bool {
+	for region := range q.Defaults {
+		if regions[region] {
+			return true
+		}
+	}
+	return false
+}
+
+func diffCatalogs(oldCatalog, newCatalog *Catalog) catalogDiff {
+	var diff catalogDiff
+	oldRegions := map[string]bool{}
+	for _, region := range oldCatalog.Regions {
+		oldRegions[region] = true
+	}
+	common := map[string]bool{}
+	for _, region := range newCatalog.Regions {
+		if oldRegions[region] {
+			common[region] = true
+		} else {
+			diff.OnlyNew = append(diff.OnlyNew, region)
+		}
+	}
+	for _, region := range oldCatalog.Regions {
+		if !common[region] {
+			diff.OnlyOld = append(diff.OnlyOld, region)
+		}
+	}
+	sort.Strings(diff.OnlyOld)
+	sort.Strings(diff.OnlyNew)
+
+	oldQuotas := map[string]catalogQuota{}
+	for _, q := range oldCatalog.Quotas {
+		if q.inRegions(common) {
+			oldQuotas[q.key()] = q
+		}
+	}
+	newQuotas := map[string]bool{}
+
+	for _, q := range newCatalog.Quotas {
+		if !q.inRegions(common) {
+			continue
+		}
+		newQuotas[q.key()] = true
+		old, ok := oldQuotas[q.key()]
+		if !ok {
+			diff.Added = append(diff.Added, q)
+			continue
+		}
+
+		sortedRegions := make([]string, 0, len(common))
+		for region := range common {
+			sortedRegions = append(sortedRegions, region)
+		}
+		sort.Strings(sortedRegions)
+
+		for _, region := range sortedRegions {
+			oldValue, hadOld := old.Defaults[region]
+			newValue, hasNew := q.Defaults[region]
+			if hadOld == hasNew && oldValue == newValue {
+				continue
+			}
+			change := catalogChange{Quota: q, Region: region}
+			if hadOld {
+				change.Old = &oldValue
+			}
+			if hasNew {
+				change.New = &newValue
+			}
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	for _, q := range oldCatalog.Quotas {
+		if _, ok := oldQuotas[q.key()]; ok && !newQuotas[q.key()] {
+			diff.Removed = append(diff.Removed, q)
+		}
+	}
+	return diff
+}
+
+func printCatalogDiff(w io.Writer, diff catalogDiff) {
+	if len(diff.OnlyOld) > 0 {
+		fmt.Fprintf(w, "Regions only in the old catalog, not compared: %s\n", strings.Join(diff.OnlyOld, ", "))
+	}
+	if len(diff.OnlyNew) > 0 {
+		fmt.Fprintf(w, "Regions only in the new catalog, not compared: %s\n", strings.Join(diff.OnlyNew, ", "))
+	}
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		fmt.Fprintln(w, "No quota changes between catalogs")
+		return
+	}
+
+	fmt.Fprintf(w, "Added quotas (%d):\n", len(diff.Added))
+	for _, q := range diff.Added {
+		fmt.Fprintf(w, "  + %s %s - %s\n", q.ServiceCode, q.QuotaCode, q.QuotaName)
+	}
+	fmt.Fprintf(w, "Removed quotas (%d):\n", len(diff.Removed))
+	for _, q := range diff.Removed {
+		fmt.Fprintf(w, "  - %s %s - %s\n", q.ServiceCode, q.QuotaCode, q.QuotaName)
+	}
+	fmt.Fprintf(w, "Changed defaults (%d):\n", len(diff.Changed))
+	for _, c := range diff.Changed {
+		fmt.Fprintf(w, "  ~ %s %s [%s] %s -> %s - %s\n", c.Quota.ServiceCode, c.Quota.QuotaCode, c.Region, formatQuotaValue(c.Old), formatQuotaValue(c.New), c.Quota.QuotaName)
+	}
+}
+
+// Export a catalog of every quota default to a file, or stdout when no path is given.
+// The cache is refreshed rather than read, so the catalog reflects AWS as of now.
+func runCatalogExport(ctx context.Context, cfg aws.Config, regions []string, outputPath string, cache *QuotaCache) {
+	catalog, err := buildCatalog(ctx, cfg, regions, cache.refreshing())
+	if err != nil {
+		log.Fatalf("❌ Error building catalog: %v", err)
+	}
+
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		log.Fatalf("❌ Error encoding catalog: %v", err)
+	}
+	data = append(data, '\n')
+
+	if outputPath == "" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		log.Fatalf("❌ Error writing catalog: %v", err)
+	}
+	log.Printf("✅ Exported catalog with %d quotas", len(catalog.Quotas))
+	os.Exit(0)
+}
+
+// Compare two catalog exports
+func runCatalogDiff(oldPath string, newPath string) {
+	oldCatalog, err := loadCatalog(oldPath)
+	if err != nil {
+		log.Fatalf("❌ Error loading catalog: %v", err)
+	}
+	newCatalog, err := loadCatalog(newPath)
+	if err != nil {
+		log.Fatalf("❌ Error loading catalog: %v", err)
+	}
+
+	printCatalogDiff(os.Stdout, diffCatalogs(oldCatalog, newCatalog))
+	os.Exit(0)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	regions := []string{"us-east-1", "eu-west-1"}
+	oldCatalog := &Catalog{
+		Regions: regions,
+		Quotas: []catalogQuota{
+			{ServiceCode: "ec2", QuotaCode: "L-1", QuotaName: "Unchanged", Defaults: map[string]float64{"us-east-1": 5, "eu-west-1": 5}},
+			{ServiceCode: "ec2", QuotaCode: "L-2", QuotaName: "Raised", Defaults: map[string]float64{"us-east-1": 10, "eu-west-1": 10}},
+			{ServiceCode: "ec2", QuotaCode: "L-3", QuotaName: "Retired", Defaults: map[string]float64{"us-east-1": 1}},
+			{ServiceCode: "lambda", QuotaCode: "L-4", QuotaName: "Expanded", Defaults: map[string]float64{"us-east-1": 50}},
+		},
+	}
+	newCatalog := &Catalog{
+		Regions: regions,
+		Quotas: []catalogQuota{
+			{ServiceCode: "ec2", QuotaCode: "L-1", QuotaName: "Unchanged", Defaults: map[string]float64{"us-east-1": 5, "eu-west-1": 5}},
+			{ServiceCode: "ec2", QuotaCode: "L-2", QuotaName: "Raised", Defaults: map[string]float64{"us-east-1": 10, "eu-west-1": 20}},
+			{ServiceCode: "lambda", QuotaCode: "L-4", QuotaName: "Expanded", Defaults: map[string]float64{"us-east-1": 50, "eu-west-1": 25}},
+			{ServiceCode: "s3", QuotaCode: "L-5", QuotaName: "Brand new", Defaults: map[string]float64{"eu-west-1": 100}},
+		},
+	}
+
+	diff := diffCatalogs(oldCatalog, newCatalog)
+	if len(diff.Added) != 1 || diff.Added[0].QuotaCode != "L-5" {
+		t.Errorf("added = %+v, want L-5", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].QuotaCode != "L-3" {
+		t.Errorf("removed = %+v, want L-3", diff.Removed)
+	}
+	var changes []string
+	for _, c := range diff.Changed {
+		changes = append(changes, c.Quota.QuotaCode+" "+c.Region+" "+formatQuotaValue(c.Old)+"->"+formatQuotaValue(c.New))
+	}
+	want := []string{"L-2 eu-west-1 10->20", "L-4 eu-west-1 " + formatQuotaValue(nil) + "->25"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changed = %v, want %v", changes, want)
+	}
+	if len(diff.OnlyOld)+len(diff.OnlyNew) != 0 {
+		t.Errorf("one-sided regions = %v / %v", diff.OnlyOld, diff.OnlyNew)
+	}
+
+	var out strings.Builder
+	printCatalogDiff(&out, diff)
+	for _, line := range []string{
+		"Added quotas (1):\n  + s3 L-5 - Brand new",
+		"Removed quotas (1):\n  - ec2 L-3 - Retired",
+		"Changed defaults (2):\n  ~ ec2 L-2 [eu-west-1] 10 -> 20 - Raised",
+	} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("diff output is missing %q:\n%s", line, out.String())
+		}
+	}
+
+	out.Reset()
+	printCatalogDiff(&out, diffCatalogs(oldCatalog, oldCatalog))
+	if out.String() != "No quota changes between catalogs\n" {
+		t.Errorf("identical catalogs printed %q", out.String())
+	}
+}
+
+func TestBuildCatalogSkipsCache(t *testing.T) {
+	useFakeServiceQuotas(t, map[string]*fakeServiceQuotas{
+		"us-east-1": {services: []string{"ec2"}, defaults: map[string][]types.ServiceQuota{"ec2": {testServiceQuota("ec2", "L-1", 5)}}},
+		"eu-west-1": {services: []string{"ec2"}, defaults: map[string][]types.ServiceQuota{"ec2": {testServiceQuota("ec2", "L-1", 8)}}},
+	})
+	dir := t.TempDir()
+	ctx := context.Background()
+	// A stale but unexpired cache entry from an earlier run
+	cache := newTestCache(dir, "123456789012", 24*time.Hour, false)
+	cache.put(ctx, aws.Config{Region: "us-east-1"}, "quotas/ec2", []quotaDefinition{{ServiceCode: "ec2", QuotaCode: "L-1", DefaultValue: aws.Float64(1)}})
+
+	fresh := cache.refreshing()
+	fresh.accountOnce.Do(func() { fresh.accountID = "123456789012" })
+	catalog, err := buildCatalog(ctx, aws.Config{}, []string{"us-east-1", "eu-west-1"}, fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Quotas) != 1 || !reflect.DeepEqual(catalog.Quotas[0].Defaults, map[string]float64{"us-east-1": 5, "eu-west-1": 8}) {
+		t.Errorf("catalog quotas = %+v, want fresh defaults", catalog.Quotas)
+	}
+
+	// The fresh values replace the stale entry for later runs
+	var defs []quotaDefinition
+	if !cache.get(ctx, aws.Config{Region: "us-east-1"}, "quotas/ec2", &defs) || *defs[0].DefaultValue != 5 {
+		t.Errorf("cache after export = %+v", defs)
+	}
+	if (*QuotaCache)(nil).refreshing() != nil {
+		t.Error("refreshing a disabled cache enabled it")
+	}
+}
+
+func TestDiffCatalogsComparesCommonRegionsOnly(t *testing.T) {
+	oldCatalog := &Catalog{
+		Regions: []string{"us-east-1", "eu-west-1"},
+		Quotas: []catalogQuota{
+			{ServiceCode: "ec2", QuotaCode: "L-1", Defaults: map[string]float64{"us-east-1": 5, "eu-west-1": 5}},
+			{ServiceCode: "ec2", QuotaCode: "L-2", Defaults: map[string]float64{"eu-west-1": 10}},
+		},
+	}
+	newCatalog := &Catalog{
+		Regions: []string{"us-east-1", "ap-south-1"},
+		Quotas: []catalogQuota{
+			{ServiceCode: "ec2", QuotaCode: "L-1", Defaults: map[string]float64{"us-east-1": 8, "ap-south-1": 5}},
+			{ServiceCode: "ec2", QuotaCode: "L-3", Defaults: map[string]float64{"ap-south-1": 1}},
+		},
+	}
+
+	diff := diffCatalogs(oldCatalog, newCatalog)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("quotas in one-sided regions reported as added/removed: %+v / %+v", diff.Added, diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Region != "us-east-1" || *diff.Changed[0].Old != 5 || *diff.Changed[0].New != 8 {
+		t.Errorf("unexpected changes: %+v", diff.Changed)
+	}
+	if !reflect.DeepEqual(diff.OnlyOld, []string{"eu-west-1"}) || !reflect.DeepEqual(diff.OnlyNew, []string{"ap-south-1"}) {
+		t.Errorf("one-sided regions = %v / %v", diff.OnlyOld, diff.OnlyNew)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	cloudWatchDefaultNamespace = "ServiceQuotaFetcher"
+	// cloudWatchMaxDatums is the most metric data PutMetricData accepts per request
+	cloudWatchMaxDatums = 1000
+)
+
+type cloudWatchPutter interface {
+	PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
+
+// CloudWatchMetrics publishes Allocated, Used and UtilizedPerc for every quota as custom metrics
+type CloudWatchMetrics struct {
+	Namespace string
+	Client    cloudWatchPutter
+}
+
+// NewCloudWatchMetrics creates a sink in cfg's region; endpoint overrides the service URL (e.g., a local stand-in)
+func NewCloudWatchMetrics(cfg aws.Config, namespace string, endpoint string) *CloudWatchMetrics {
+	client := cloudwatch.NewFromConfig(cfg, func(o *cloudwatch.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return &CloudWatchMetrics{Namespace: namespace, Client: client}
+}
+
+func (c *CloudWatchMetrics) Name() string {
+	return "CloudWatch namespace " + c.Namespace
+}
+
+// cloudWatchDatums turns each quota into its three metrics, dimensioned by service, quota code and region
+func cloudWatchDatums(report *Report) []cwtypes.MetricDatum {
+	datums := make([]cwtypes.MetricDatum, 0, len(report.Quotas)*3)
+	for _, q := range report.Quotas {
+		dimensions := []cwtypes.Dimension{
+			{Name: aws.String("Service"), Value: aws.String(q.ServiceName)},
+			{Name: aws.String("QuotaCode"), Value: aws.String(q.QuotaCode)},
+			{Name: aws.String("Region"), Value: aws.String(q.Region)},
+		}
+		for _, m := range []struct {
+			name  string
+			value float64
+			unit  cwtypes.StandardUnit
+		}{
+			{"Allocated", q.Allocated, cwtypes.StandardUnitNone},
+			{"Used", q.Used, cwtypes.StandardUnitNone},
+			{"UtilizedPerc", q.UtilizedPerc, cwtypes.StandardUnitPercent},
+		} {
+			datums = append(datums, cwtypes.MetricDatum{
+				MetricName: aws.String(m.name),
+				Dimensions: dimensions,
+				Timestamp:  aws.Time(report.GeneratedAt),
+				Unit:       m.unit,
+				Value:      aws.Float64(m.value),
+			})
+		}
+	}
+	return datums
+}
+
+func (c *CloudWatchMetrics) Publish(ctx context.Context, report *Report) error {
+	datums := cloudWatchDatums(report)
+	for start := 0; start < len(datums); start += cloudWatchMaxDatums {
+		end := min(start+cloudWatchMaxDatums, len(datums))
+		_, err := c.Client.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(c.Namespace),
+			MetricData: datums[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("error putting metrics %d-%d of %d: %v", start+1, end, len(datums), err)
+		}
+	}
+	return nil
+}
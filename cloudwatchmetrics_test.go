@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+// fakePutter records PutMetricData calls and optionally fails one of them
+type fakePutter struct {
+	calls  []*cloudwatch.PutMetricDataInput
+	failAt int // 1-based call to fail; 0 never fails
+}
+
+func (f *fakePutter) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	f.calls = append(f.calls, params)
+	if len(f.calls) == f.failAt {
+		return nil, fmt.Errorf("throttled")
+	}
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+func TestCloudWatchMetricsBatching(t *testing.T) {
+	// 700 quotas are 2100 datums: two full batches and a partial one
+	putter := &fakePutter{}
+	sink := &CloudWatchMetrics{Namespace: "Platform/Quotas", Client: putter}
+	report := newTestReport(700)
+	if err := sink.Publish(context.Background(), report); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	sizes := []int{}
+	for _, call := range putter.calls {
+		if aws.ToString(call.Namespace) != "Platform/Quotas" {
+			t.Errorf("namespace = %q", aws.ToString(call.Namespace))
+		}
+		sizes = append(sizes, len(call.MetricData))
+	}
+	if fmt.Sprint(sizes) != "[1000 1000 100]" {
+		t.Errorf("batch sizes = %v, want [1000 1000 100]", sizes)
+	}
+
+	first := putter.calls[0].MetricData
+	q := report.Quotas[0]
+	wantDims := map[string]string{"Service": q.ServiceName, "QuotaCode": q.QuotaCode, "Region": q.Region}
+	for i, name := range []string{"Allocated", "Used", "UtilizedPerc"} {
+		datum := first[i]
+		if aws.ToString(datum.MetricName) != name {
+			t.Errorf("datum %d is %s, want %s", i, aws.ToString(datum.MetricName), name)
+		}
+		if len(datum.Dimensions) != len(wantDims) {
+			t.Errorf("%s has %d dimensions, want %d", name, len(datum.Dimensions), len(wantDims))
+		}
+		for _, d := range datum.Dimensions {
+			if want := wantDims[aws.ToString(d.Name)]; aws.ToString(d.Value) != want {
+				t.Errorf("%s dimension %s = %q, want %q", name, aws.ToString(d.Name), aws.ToString(d.Value), want)
+			}
+		}
+		if !datum.Timestamp.Equal(report.GeneratedAt) {
+			t.Errorf("%s timestamp = %v", name, datum.Timestamp)
+		}
+	}
+	if v := aws.ToFloat64(first[2].Value); v != q.UtilizedPerc || first[2].Unit != "Percent" {
+		t.Errorf("UtilizedPerc = %v %s", v, first[2].Unit)
+	}
+}
+
+func TestCloudWatchMetricsReportsFailedBatch(t *testing.T) {
+	putter := &fakePutter{failAt: 2}
+	sink := &CloudWatchMetrics{Namespace: "Platform/Quotas", Client: putter}
+	err := sink.Publish(context.Background(), newTestReport(700))
+	if err == nil || err.Error() != "error putting metrics 1001-2000 of 2100: throttled" {
+		t.Errorf("Publish error = %v", err)
+	}
+	if len(putter.calls) != 2 {
+		t.Errorf("kept publishing after a failure: %d calls", len(putter.calls))
+	}
+}
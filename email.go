@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emailMaxChanges caps the week-over-week table so digests stay readable
+const emailMaxChanges = 20
+
+var emailTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; color: #1f2328;">
+<h2>AWS Service Quota Report</h2>
+<p>Account <b>{{.Report.AccountID}}</b> &middot; Regions {{.Regions}} &middot; Run {{.RunTime}}</p>
+<table cellpadding="6" style="border-collapse: collapse;">
+<tr>
+<td style="background: #d1242f; color: #fff;"><b>{{.Critical}}</b> critical</td>
+<td style="background: #bf8700; color: #fff;"><b>{{.Warning}}</b> warning</td>
+<td style="background: #1a7f37; color: #fff;"><b>{{.OK}}</b> ok</td>
+</tr>
+</table>
+<p>Thresholds: warning &ge; {{printf "%.0f" .Report.Thresholds.Warning}}%, critical &ge; {{printf "%.0f" .Report.Thresholds.Critical}}%</p>
+{{if .Report.Events}}
+<h3>Alert changes</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse; border-color: #d0d7de;">
+<tr style="background: #f6f8fa;"><th>Change</th><th>Service</th><th>Region</th><th>Quota</th><th>Before</th><th>Now</th><th>Utilized</th></tr>
+{{range .Report.Events}}<tr>
+<td><b>{{.Kind.Label}}</b></td><td>{{.Quota.ServiceName}}</td><td>{{.Quota.Region}}</td><td>{{.Quota.QuotaName}}</td>
+<td>{{.Previous}}</td><td>{{.Severity}}</td><td align="right">{{printf "%.2f" .Quota.UtilizedPerc}}%</td>
+</tr>
+{{end}}</table>
+{{end}}
+{{if .Breaching}}
+<h3>Quotas above threshold</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse; border-color: #d0d7de;">
+<tr style="background: #f6f8fa;"><th>Severity</th><th>Service</th><th>Region</th><th>Quota</th><th>Code</th><th>Used</th><th>Limit</th><th>Utilized</th></tr>
+{{range .Breaching}}<tr>
+<td style="color: {{.Color}};"><b>{{.Severity}}</b></td><td>{{.Quota.ServiceName}}</td><td>{{.Quota.Region}}</td><td>{{.Quota.QuotaName}}</td><td>{{.Quota.QuotaCode}}</td>
+<td align="right">{{printf "%.0f" .Quota.Used}}</td><td align="right">{{printf "%.0f" .Quota.Allocated}}</td><td align="right">{{printf "%.2f" .Quota.UtilizedPerc}}%</td>
+</tr>
+{{end}}</table>
+{{else}}
+<p>&#9989; No quotas at or above {{printf "%.0f" .Report.Thresholds.Warning}}% utilization.</p>
+{{end}}
+{{if .Changes}}
+<h3>Changes since previous report</h3>
+<table cellpadding="4" border="1" style="border-collapse: collapse; border-color: #d0d7de;">
+<tr style="background: #f6f8fa;"><th>Service</th><th>Region</th><th>Quota</th><th>Before</th><th>Now</th><th>Change</th></tr>
+{{range .Changes}}<tr>
+<td>{{.ServiceName}}</td><td>{{.Region}}</td><td>{{.QuotaName}}</td>
+<td align="right">{{printf "%.2f" .Before}}%</td><td align="right">{{printf "%.2f" .Now}}%</td><td align="right">{{printf "%+.2f" .Delta}}</td>
+</tr>
+{{end}}</table>
+{{end}}
+<p style="color: #656d76; font-size: small;">The full report is attached as CSV. Generated by awsservicesquotafetcher {{.Version}}.</p>
+</body>
+</html>
+`))
+
+type emailRow struct {
+	Quota    QuotaInfo
+	Severity Severity
+	Color    string
+}
+
+type emailChange struct {
+	ServiceName string
+	Region      string
+	QuotaName   string
+	Before      float64
+	Now         float64
+	Delta       float64
+}
+
+// quotaChanges compares utilization with a previous report, biggest moves first.
+// Quotas are matched by service, name and region because older CSVs carry no quota code.
+func quotaChanges(previous []QuotaInfo, current []QuotaInfo) []emailChange {
+	before := map[string]float64{}
+	for _, q := range previous {
+		before[q.ServiceName+"\x00"+q.QuotaName+"\x00"+q.Region] = q.UtilizedPerc
+	}
+
+	var changes []emailChange
+	for _, q := range current {
+		old, ok := before[q.ServiceName+"\x00"+q.QuotaName+"\x00"+q.Region]
+		if !ok || math.Abs(q.UtilizedPerc-old) < 0.01 {
+			continue
+		}
+		changes = append(changes, emailChange{
+			ServiceName: q.ServiceName,
+			Region:      q.Region,
+			QuotaName:   q.QuotaName,
+			Before:      old,
+			Now:         q.UtilizedPerc,
+			Delta:       q.UtilizedPerc - old,
+		})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return math.Abs(changes[i].Delta) > math.Abs(changes[j].Delta) })
+	if len(changes) > emailMaxChanges {
+		changes = changes[:emailMaxChanges]
+	}
+	return changes
+}
+
+// EmailNotifier sends an HTML digest with the CSV report attached over SMTP
+type EmailNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	StartTLS bool // require STARTTLS before authenticating
+
+	// Recipients by the report's highest severity: SeverityOK recipients get every
+	// digest, SeverityWarning ones when anything breaches, SeverityCritical ones only on critical
+	Recipients map[Severity][]string
+
+	// Previous is an earlier report used for the change table; nil omits it
+	Previous []QuotaInfo
+}
+
+func (n *EmailNotifier) Name() string {
+	return "email via " + n.Host
+}
+
+// recipients returns everyone subscribed at or below the report's highest severity, or the
+// severity quotas recovered or de-escalated from, so whoever heard of a breach hears it ended
+func (n *EmailNotifier) recipients(report *Report) ([]string, Severity) {
+	highest := SeverityOK
+	for _, q := range report.Quotas {
+		if s := report.Severity(q); s > highest {
+			highest = s
+		}
+	}
+	level := highest
+	for _, e := range report.Events {
+		if e.Previous > level {
+			level = e.Previous
+		}
+	}
+
+	seen := map[string]bool{}
+	var to []string
+	for s := SeverityOK; s <= level; s++ {
+		for _, addr := range n.Recipients[s] {
+			if !seen[addr] {
+				seen[addr] = true
+				to = append(to, addr)
+			}
+		}
+	}
+	return to, highest
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, report *Report) error {
+	to, highest := n.recipients(report)
+	if len(to) == 0 {
+		return nil
+	}
+	msg, err := n.buildMessage(report, to, highest)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, to, msg)
+}
+
+func (n *EmailNotifier) renderHTML(report *Report) ([]byte, error) {
+	counts := report.SeverityCounts()
+	var rows []emailRow
+	for _, q := range report.Breaching() {
+		s := report.Severity(q)
+		color := "#bf8700"
+		if s == SeverityCritical {
+			color = "#d1242f"
+		}
+		rows = append(rows, emailRow{Quota: q, Severity: s, Color: color})
+	}
+
+	var buf bytes.Buffer
+	err := emailTemplate.Execute(&buf, map[string]interface{}{
+		"Report":    report,
+		"Regions":   strings.Join(report.Regions, ", "),
+		"RunTime":   report.GeneratedAt.UTC().Format(time.RFC1123),
+		"Critical":  counts[SeverityCritical],
+		"Warning":   counts[SeverityWarning],
+		"OK":        counts[SeverityOK],
+		"Breaching": rows,
+		"Changes":   quotaChanges(n.Previous, report.Quotas),
+		"Version":   version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error rendering email: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (n *EmailNotifier) buildMessage(report *Report, to []string, highest Severity) ([]byte, error) {
+	html, err := n.renderHTML(report)
+	if err != nil {
+		return nil, err
+	}
+	var csvData bytes.Buffer
+	if err := writeCSV(&csvData, report.Quotas); err != nil {
+		return nil, fmt.Errorf("error encoding CSV attachment: %v", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	htmlPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64Lines(htmlPart, html)
+
+	filename := fmt.Sprintf("quota-report-%s-%s.csv", report.AccountID, report.GeneratedAt.UTC().Format("20060102"))
+	csvPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/csv; charset=UTF-8"},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64Lines(csvPart, csvData.Bytes())
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	counts := report.SeverityCounts()
+	subject := fmt.Sprintf("[%s] AWS quota report for %s: %d critical, %d warning",
+		strings.ToUpper(highest.String()), report.AccountID, counts[SeverityCritical], counts[SeverityWarning])
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", report.GeneratedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Message-ID: <%s@awsservicesquotafetcher>\r\n", randomID())
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writeBase64Lines writes base64 wrapped at 76 characters as MIME requires
+func writeBase64Lines(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+func (n *EmailNotifier) send(ctx context.Context, to []string, msg []byte) error {
+	addr := net.JoinHostPort(n.Host, strconv.Itoa(n.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %v", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(notifyHTTPTimeout))
+	}
+
+	client, err := smtp.NewClient(conn, n.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error starting SMTP session: %v", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.Host}); err != nil {
+			return fmt.Errorf("error starting TLS: %v", err)
+		}
+	} else if n.StartTLS {
+		return fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+	}
+
+	if n.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.Username, n.Password, n.Host)); err != nil {
+			return fmt.Errorf("error authenticating: %v", err)
+		}
+	}
+
+	if err := client.Mail(n.From); err != nil {
+		return fmt.Errorf("error setting sender: %v", err)
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("error adding recipient %s: %v", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting message: %v", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("error writing message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts one session and records its envelope and message
+type fakeSMTPServer struct {
+	listener net.Listener
+	done     chan struct{}
+	from     string
+	rcpts    []string
+	data     string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTPServer{listener: listener, done: make(chan struct{})}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP fake")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250 localhost")
+		case "MAIL":
+			s.from = arg
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.rcpts = append(s.rcpts, arg)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 go ahead")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.data = strings.Join(lines, "\n")
+			tp.PrintfLine("250 queued")
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+		default:
+			tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestEmailNotifierSend(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := &EmailNotifier{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "quotas@example.com",
+		Recipients: map[Severity][]string{
+			SeverityOK:       {"team@example.com"},
+			SeverityWarning:  {"oncall@example.com", "team@example.com"},
+			SeverityCritical: {"cto@example.com"},
+		},
+	}
+
+	// newTestReport quotas are at most 99% utilized, some critical
+	if err := n.Notify(context.Background(), newTestReport(10)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	<-server.done
+
+	if server.from != "FROM:<quotas@example.com>" {
+		t.Errorf("MAIL %s", server.from)
+	}
+	want := []string{"TO:<team@example.com>", "TO:<oncall@example.com>", "TO:<cto@example.com>"}
+	if strings.Join(server.rcpts, ",") != strings.Join(want, ",") {
+		t.Errorf("RCPT %v, want %v", server.rcpts, want)
+	}
+	for _, header := range []string{"Subject: [CRITICAL] AWS quota report for 123456789012", "Content-Type: multipart/mixed", "text/csv"} {
+		if !strings.Contains(server.data, header) {
+			t.Errorf("message lacks %q", header)
+		}
+	}
+}
+
+func TestEmailNotifierRequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := &EmailNotifier{Host: "127.0.0.1", Port: server.port(), From: "quotas@example.com", StartTLS: true}
+	err := n.send(context.Background(), []string{"team@example.com"}, []byte("Subject: test\r\n\r\nbody\r\n"))
+	want := "SMTP server 127.0.0.1:" + strconv.Itoa(server.port()) + " does not support STARTTLS"
+	if err == nil || err.Error() != want {
+		t.Errorf("send error = %v, want %q", err, want)
+	}
+}
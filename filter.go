@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// quotaFilter narrows and orders fetched quotas before any output or notification
+type quotaFilter struct {
+	QuotaCodes     map[string]bool
+	NameRegex      *regexp.Regexp
+	Exclude        *regexp.Regexp
+	MinUtilization float64
+	OnlyUsed       bool
+	OnlyAdjustable bool
+	SortBy         string
+	Top            int
+}
+
+// newQuotaFilter validates filter flags; empty values disable the matching filter
+func newQuotaFilter(quotaCodes string, nameRegex string, exclude string, minUtilization float64, onlyUsed bool, onlyAdjustable bool, sortBy string, top int) (*quotaFilter, error) {
+	f := &quotaFilter{
+		MinUtilization: minUtilization,
+		OnlyUsed:       onlyUsed,
+		OnlyAdjustable: onlyAdjustable,
+		SortBy:         sortBy,
+		Top:            top,
+	}
+
+	if quotaCodes != "" {
+		f.QuotaCodes = map[string]bool{}
+		for _, code := range strings.Split(quotaCodes, ",") {
+			f.QuotaCodes[strings.TrimSpace(code)] = true
+		}
+	}
+
+	var err error
+	if nameRegex != "" {
+		if f.NameRegex, err = regexp.Compile("(?i)" + nameRegex); err != nil {
+			return nil, fmt.Errorf("invalid --name-regex: %v", err)
+		}
+	}
+	if exclude != "" {
+		if f.Exclude, err = regexp.Compile("(?i)" + exclude); err != nil {
+			return nil, fmt.Errorf("invalid --exclude: %v", err)
+		}
+	}
+
+	switch sortBy {
+	case "", "utilization", "name", "headroom":
+	default:
+		return nil, fmt.Errorf("invalid --sort %q (use utilization, name or headroom)", sortBy)
+	}
+	if top < 0 {
+		return nil, fmt.Errorf("invalid --top %d", top)
+	}
+	return f, nil
+}
+
+func (f *quotaFilter) match(q QuotaInfo) bool {
+	if f.QuotaCodes != nil && !f.QuotaCodes[q.QuotaCode] {
+		return false
+	}
+	if f.NameRegex != nil && !f.NameRegex.MatchString(q.QuotaName) {
+		return false
+	}
+	if f.Exclude != nil && (f.Exclude.MatchString(q.QuotaName) || f.Exclude.MatchString(q.QuotaCode)) {
+		return false
+	}
+	if q.UtilizedPerc < f.MinUtilization {
+		return false
+	}
+	if f.OnlyUsed && q.Used <= 0 {
+		return false
+	}
+	if f.OnlyAdjustable && !q.Adjustable {
+		return false
+	}
+	return true
+}
+
+// Apply returns the matching quotas, sorted and truncated to the top N
+func (f *quotaFilter) Apply(quotas []QuotaInfo) []QuotaInfo {
+	var kept []QuotaInfo
+	for _, q := range quotas {
+		if f.match(q) {
+			kept = append(kept, q)
+		}
+	}
+
+	switch f.SortBy {
+	case "utilization":
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].UtilizedPerc > kept[j].UtilizedPerc })
+	case "name":
+		sort.SliceStable(kept, func(i, j int) bool { return kept[i].QuotaName < kept[j].QuotaName })
+	case "headroom":
+		sort.SliceStable(kept, func(i, j int) bool {
+			return kept[i].Allocated-kept[i].Used < kept[j].Allocated-kept[j].Used
+		})
+	}
+
+	if f.Top > 0 && len(kept) > f.Top {
+		kept = kept[:f.Top]
+	}
+	return kept
+}
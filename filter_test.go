@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuotaFilterApply(t *testing.T) {
+	quotas := []QuotaInfo{
+		{QuotaCode: "L-1", QuotaName: "Running On-Demand Standard instances", Allocated: 100, Used: 90, UtilizedPerc: 90, Adjustable: true},
+		{QuotaCode: "L-2", QuotaName: "EC2-VPC Elastic IPs", Allocated: 5, Used: 1, UtilizedPerc: 20, Adjustable: true},
+		{QuotaCode: "L-3", QuotaName: "Concurrent executions", Allocated: 1000, Used: 700, UtilizedPerc: 70},
+		{QuotaCode: "L-4", QuotaName: "Attachments per VPC", Allocated: 10, Used: 0, UtilizedPerc: 0},
+	}
+	codes := func(qs []QuotaInfo) []string {
+		out := []string{}
+		for _, q := range qs {
+			out = append(out, q.QuotaCode)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name           string
+		quotaCodes     string
+		nameRegex      string
+		exclude        string
+		minUtilization float64
+		onlyUsed       bool
+		onlyAdjustable bool
+		sortBy         string
+		top            int
+		want           []string
+	}{
+		{name: "no filters", want: []string{"L-1", "L-2", "L-3", "L-4"}},
+		{name: "quota codes", quotaCodes: "L-3, L-1", want: []string{"L-1", "L-3"}},
+		{name: "name regex is case-insensitive", nameRegex: "^ec2-vpc|^running", want: []string{"L-1", "L-2"}},
+		{name: "exclude matches names", exclude: "vpc", want: []string{"L-1", "L-3"}},
+		{name: "exclude matches codes", exclude: "^L-[12]$", want: []string{"L-3", "L-4"}},
+		{name: "min utilization", minUtilization: 70, want: []string{"L-1", "L-3"}},
+		{name: "only used", onlyUsed: true, want: []string{"L-1", "L-2", "L-3"}},
+		{name: "adjustable only", onlyAdjustable: true, want: []string{"L-1", "L-2"}},
+		{name: "sort by utilization", sortBy: "utilization", want: []string{"L-1", "L-3", "L-2", "L-4"}},
+		{name: "sort by name", sortBy: "name", want: []string{"L-4", "L-3", "L-2", "L-1"}},
+		{name: "sort by headroom", sortBy: "headroom", want: []string{"L-2", "L-1", "L-4", "L-3"}},
+		{name: "top after sorting", sortBy: "utilization", top: 2, want: []string{"L-1", "L-3"}},
+		{name: "top larger than the list", top: 10, want: []string{"L-1", "L-2", "L-3", "L-4"}},
+		{name: "filters combine", nameRegex: "e", minUtilization: 10, onlyAdjustable: true, sortBy: "headroom", top: 1, want: []string{"L-2"}},
+		{name: "nothing matches", quotaCodes: "L-9", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newQuotaFilter(tt.quotaCodes, tt.nameRegex, tt.exclude, tt.minUtilization, tt.onlyUsed, tt.onlyAdjustable, tt.sortBy, tt.top)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := codes(f.Apply(quotas)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewQuotaFilterErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		nameRegex string
+		exclude   string
+		sortBy    string
+		top       int
+	}{
+		{name: "bad name regex", nameRegex: "("},
+		{name: "bad exclude", exclude: "[a-"},
+		{name: "unknown sort key", sortBy: "region"},
+		{name: "negative top", top: -1},
+	}
+	for _, tt := range tests {
+		if _, err := newQuotaFilter("", tt.nameRegex, tt.exclude, 0, false, false, tt.sortBy, tt.top); err == nil {
+			t.Errorf("%s: newQuotaFilter succeeded", tt.name)
+		}
+	}
+}
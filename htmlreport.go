@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// htmlReportData is what the HTML report template renders
+type htmlReportData struct {
+	AccountID   string
+	Regions     string
+	GeneratedAt string
+	Version     string
+	Thresholds  Thresholds
+	Total       int
+	Counts      map[string]int
+	Services    int
+	Quotas      []htmlQuotaRow
+	Metadata    map[string]interface{}
+}
+
+type htmlQuotaRow struct {
+	quotaRecord
+	Bar float64 // utilization capped at 100 for the bar width
+}
+
+// formatPercent formats a utilization percentage the way the console output does
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.2f%%", v)
+}
+
+// writeHTML writes a single static page with summary cards, a sortable, filterable
+// and groupable quota table, and the run metadata embedded as JSON
+func writeHTML(w io.Writer, report *Report) error {
+	data := htmlReportData{
+		AccountID:   report.AccountID,
+		Regions:     strings.Join(report.Regions, ", "),
+		GeneratedAt: report.GeneratedAt.UTC().Format(time.RFC1123),
+		Version:     version,
+		Thresholds:  report.Thresholds,
+		Total:       len(report.Quotas),
+		Counts:      map[string]int{},
+	}
+	for severity, n := range report.SeverityCounts() {
+		data.Counts[severity.String()] = n
+	}
+
+	services := map[string]bool{}
+	for _, q := range report.Quotas {
+		services[q.ServiceName] = true
+		data.Quotas = append(data.Quotas, htmlQuotaRow{quotaRecord: newQuotaRecord(report, q), Bar: min(max(q.UtilizedPerc, 0), 100)})
+	}
+	data.Services = len(services)
+	sort.SliceStable(data.Quotas, func(i, j int) bool { return data.Quotas[i].UtilizedPerc > data.Quotas[j].UtilizedPerc })
+
+	data.Metadata = map[string]interface{}{
+		"account_id":         report.AccountID,
+		"regions":            report.Regions,
+		"generated_at":       report.GeneratedAt.UTC().Format(time.RFC3339),
+		"tool_version":       version,
+		"warning_threshold":  report.Thresholds.Warning,
+		"critical_threshold": report.Thresholds.Critical,
+		"quotas":             len(report.Quotas),
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": formatPercent,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>AWS Service Quotas – {{.AccountID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { margin-bottom: .25rem; }
+.meta { color: #656d76; margin-bottom: 1.5rem; }
+.cards { display: flex; gap: 1rem; flex-wrap: wrap; margin-bottom: 1.5rem; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: .75rem 1.25rem; min-width: 8rem; }
+.card .value { font-size: 1.75rem; font-weight: 600; }
+.card.critical .value { color: #cf222e; }
+.card.warning .value { color: #9a6700; }
+.card.ok .value { color: #1a7f37; }
+.controls { display: flex; gap: .75rem; margin-bottom: 1rem; }
+.controls input { flex: 1; max-width: 24rem; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+th, td { border-bottom: 1px solid #d0d7de; padding: .4rem .6rem; text-align: left; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; position: sticky; top: 0; }
+th.sorted-asc::after { content: " ▲"; }
+th.sorted-desc::after { content: " ▼"; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+tr.group td { background: #eaeef2; font-weight: 600; }
+.bar { background: #eaeef2; border-radius: 3px; height: .6rem; width: 8rem; display: inline-block; vertical-align: middle; margin-right: .5rem; }
+.bar span { display: block; height: 100%; border-radius: 3px; }
+.bar .ok { background: #2da44e; }
+.bar .warning { background: #d4a72c; }
+.bar .critical { background: #cf222e; }
+</style>
+</head>
+<body>
+<h1>AWS Service Quotas</h1>
+<div class="meta">Account {{.AccountID}} · Regions {{.Regions}} · Generated {{.GeneratedAt}} · awsservicesquotafetcher {{.Version}} · Warning at {{percent .Thresholds.Warning}}, critical at {{percent .Thresholds.Critical}}</div>
+
+<div class="cards">
+<div class="card"><div>Quotas</div><div class="value">{{.Total}}</div></div>
+<div class="card"><div>Services</div><div class="value">{{.Services}}</div></div>
+<div class="card critical"><div>Critical</div><div class="value">{{index .Counts "critical"}}</div></div>
+<div class="card warning"><div>Warning</div><div class="value">{{index .Counts "warning"}}</div></div>
+<div class="card ok"><div>OK</div><div class="value">{{index .Counts "ok"}}</div></div>
+</div>
+
+<div class="controls">
+<input id="filter" type="search" placeholder="Filter by service, quota, code or region">
+<select id="severity"><option value="">All severities</option><option>critical</option><option>warning</option><option>ok</option></select>
+<select id="group"><option value="">No grouping</option><option value="account">Group by account</option><option value="region">Group by region</option><option value="service">Group by service</option></select>
+</div>
+
+<table id="quotas">
+<thead><tr>
+<th data-key="account">Account</th><th data-key="region">Region</th><th data-key="service">Service</th><th data-key="code">Quota Code</th><th data-key="name">Quota Name</th>
+<th data-key="allocated" data-num>Allocated</th><th data-key="used" data-num>Used</th><th data-key="utilization" data-num>Utilization</th><th data-key="adjustable">Adjustable</th>
+</tr></thead>
+<tbody>
+{{- range .Quotas}}
+<tr data-account="{{.Account}}" data-region="{{.Region}}" data-service="{{.Service}}" data-code="{{.QuotaCode}}" data-name="{{.QuotaName}}" data-allocated="{{.Allocated}}" data-used="{{.Used}}" data-utilization="{{.UtilizedPerc}}" data-adjustable="{{.Adjustable}}" data-severity="{{.Severity}}">
+<td>{{.Account}}</td><td>{{.Region}}</td><td>{{.Service}}</td><td>{{.QuotaCode}}</td><td>{{.QuotaName}}</td>
+<td class="num">{{.Allocated}}</td><td class="num">{{.Used}}</td>
+<td class="num"><span class="bar"><span class="{{.Severity}}" style="width: {{.Bar}}%"></span></span>{{percent .UtilizedPerc}}</td>
+<td>{{if .Adjustable}}yes{{else}}no{{end}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+
+<script type="application/json" id="run-metadata">{{.Metadata}}</script>
+<script>
+(function () {
+  var table = document.getElementById("quotas");
+  var body = table.tBodies[0];
+  var rows = Array.prototype.slice.call(body.rows);
+  var sortKey = "utilization", sortNum = true, sortDir = -1;
+
+  function value(row, key, num) {
+    var v = row.dataset[key];
+    return num ? parseFloat(v) : v.toLowerCase();
+  }
+
+  function render() {
+    var text = document.getElementById("filter").value.toLowerCase();
+    var severity = document.getElementById("severity").value;
+    var group = document.getElementById("group").value;
+    var visible = rows.filter(function (row) {
+      if (severity && row.dataset.severity !== severity) return false;
+      return !text || row.textContent.toLowerCase().indexOf(text) !== -1;
+    });
+    visible.sort(function (a, b) {
+      if (group) {
+        var ga = value(a, group), gb = value(b, group);
+        if (ga !== gb) return ga < gb ? -1 : 1;
+      }
+      var va = value(a, sortKey, sortNum), vb = value(b, sortKey, sortNum);
+      return va === vb ? 0 : (va < vb ? -sortDir : sortDir);
+    });
+
+    body.textContent = "";
+    var current = null;
+    visible.forEach(function (row) {
+      if (group && row.dataset[group] !== current) {
+        current = row.dataset[group];
+        var count = visible.filter(function (r) { return r.dataset[group] === current; }).length;
+        var header = body.insertRow();
+        header.className = "group";
+        var cell = header.insertCell();
+        cell.colSpan = 9;
+        cell.textContent = current + " (" + count + ")";
+      }
+      body.appendChild(row);
+    });
+  }
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (th) {
+    th.addEventListener("click", function () {
+      var key = th.dataset.key;
+      sortDir = key === sortKey ? -sortDir : (th.hasAttribute("data-num") ? -1 : 1);
+      sortKey = key;
+      sortNum = th.hasAttribute("data-num");
+      Array.prototype.forEach.call(table.tHead.rows[0].cells, function (c) { c.className = ""; });
+      th.className = sortDir > 0 ? "sorted-asc" : "sorted-desc";
+      render();
+    });
+  });
+  ["filter", "severity", "group"].forEach(function (id) {
+    document.getElementById(id).addEventListener("input", render);
+  });
+  render();
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// findElements returns the elements with a tag name below n, in document order
+func findElements(n *html.Node, tag string) []*html.Node {
+	var found []*html.Node
+	if n.Type == html.ElementNode && n.Data == tag {
+		found = append(found, n)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		found = append(found, findElements(c, tag)...)
+	}
+	return found
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func TestWriteHTML(t *testing.T) {
+	report := newTestReport(5)
+	report.Quotas[0].QuotaName = `</script><script>alert("x")</script>`
+
+	var buf bytes.Buffer
+	if err := writeHTML(&buf, report); err != nil {
+		t.Fatalf("writeHTML: %v", err)
+	}
+	doc, err := html.Parse(&buf)
+	if err != nil {
+		t.Fatalf("report does not parse: %v", err)
+	}
+
+	var metadata map[string]interface{}
+	var scripts int
+	for _, s := range findElements(doc, "script") {
+		if attr(s, "id") != "run-metadata" {
+			scripts++
+			continue
+		}
+		if attr(s, "type") != "application/json" || s.FirstChild == nil {
+			t.Fatal("run metadata is not a JSON script element")
+		}
+		if err := json.Unmarshal([]byte(s.FirstChild.Data), &metadata); err != nil {
+			t.Fatalf("run metadata is not valid JSON: %v\n%s", err, s.FirstChild.Data)
+		}
+	}
+	if scripts != 1 {
+		t.Errorf("found %d inline scripts, want 1; a quota name escaped its cell", scripts)
+	}
+	if metadata["account_id"] != report.AccountID || metadata["quotas"] != float64(len(report.Quotas)) || metadata["critical_threshold"] != 90.0 {
+		t.Errorf("metadata = %v", metadata)
+	}
+
+	tables := findElements(doc, "tbody")
+	if len(tables) != 1 {
+		t.Fatalf("found %d table bodies, want 1", len(tables))
+	}
+	rows := findElements(tables[0], "tr")
+	if len(rows) != len(report.Quotas) {
+		t.Errorf("table has %d rows, want %d", len(rows), len(report.Quotas))
+	}
+	// Rows are sorted by utilization, highest first
+	if first := attr(rows[0], "data-utilization"); first != "89" {
+		t.Errorf("first row utilization = %s, want 89", first)
+	}
+	found := false
+	for _, row := range rows {
+		found = found || strings.Contains(attr(row, "data-name"), "</script>")
+	}
+	if !found {
+		t.Error("the escaped quota name did not round-trip through its data attribute")
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	pagerDutyDefaultURL = "https://events.pagerduty.com/v2/enqueue"
+	opsgenieDefaultURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// quotaDetails are the custom fields attached to every incident
+func quotaDetails(report *Report, q QuotaInfo) map[string]string {
+	return map[string]string{
+		"account":     report.AccountID,
+		"region":      q.Region,
+		"service":     q.ServiceName,
+		"quota_code":  q.QuotaCode,
+		"quota_name":  q.QuotaName,
+		"limit":       fmt.Sprintf("%.2f", q.Allocated),
+		"usage":       fmt.Sprintf("%.2f", q.Used),
+		"utilization": fmt.Sprintf("%.2f%%", q.UtilizedPerc),
+		"severity":    report.Severity(q).String(),
+	}
+}
+
+func incidentSummary(report *Report, q QuotaInfo) string {
+	return fmt.Sprintf("%s quota %s at %.2f%% in %s (%s): %.0f/%.0f",
+		q.ServiceName, q.QuotaName, q.UtilizedPerc, q.Region, report.AccountID, q.Used, q.Allocated)
+}
+
+// incidentAPI opens and resolves a single alert per quota
+type incidentAPI interface {
+	Trigger(ctx context.Context, report *Report, q QuotaInfo, key string) error
+	Resolve(ctx context.Context, report *Report, q QuotaInfo, key string) error
+}
+
+// IncidentNotifier triggers one alert per quota at or above MinSeverity and, when alert
+// state is tracked, resolves it on a later run once the quota drops back below
+type IncidentNotifier struct {
+	Label       string
+	API         incidentAPI
+	MinSeverity Severity
+}
+
+func (n *IncidentNotifier) Name() string {
+	return n.Label
+}
+
+func (n *IncidentNotifier) Notify(ctx context.Context, report *Report) error {
+	var errs []error
+	trigger := func(q QuotaInfo, key string) {
+		if err := n.API.Trigger(ctx, report, q, key); err != nil {
+			errs = append(errs, fmt.Errorf("trigger %s: %v", key, err))
+		}
+	}
+
+	// Without alert state there is no memory of open alerts, so only trigger
+	if report.Events == nil {
+		for _, q := range report.Quotas {
+			if report.Severity(q) >= n.MinSeverity {
+				trigger(q, quotaKey(report, q))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, e := range report.Events {
+		switch {
+		case e.Severity >= n.MinSeverity && e.Kind != AlertDeescalated:
+			trigger(e.Quota, e.Key)
+		case e.Severity < n.MinSeverity && e.Previous >= n.MinSeverity:
+			if err := n.API.Resolve(ctx, report, e.Quota, e.Key); err != nil {
+				errs = append(errs, fmt.Errorf("resolve %s: %v", e.Key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// postJSON sends a JSON body and expects a 2xx answer
+func postJSON(ctx context.Context, client *http.Client, target string, headers map[string]string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error marshaling payload: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// PagerDutyEvents sends alerts through the PagerDuty Events API v2
+type PagerDutyEvents struct {
+	RoutingKey string
+	URL        string
+	Client     *http.Client
+}
+
+func (p *PagerDutyEvents) event(action string, key string, payload map[string]interface{}) map[string]interface{} {
+	event := map[string]interface{}{
+		"routing_key":  p.RoutingKey,
+		"event_action": action,
+		"dedup_key":    key,
+	}
+	if payload != nil {
+		event["payload"] = payload
+	}
+	return event
+}
+
+func (p *PagerDutyEvents) Trigger(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	severity := "warning"
+	if report.Severity(q) == SeverityCritical {
+		severity = "critical"
+	}
+	return postJSON(ctx, p.Client, p.URL, nil, p.event("trigger", key, map[string]interface{}{
+		"summary":        truncate(incidentSummary(report, q), 1024),
+		"source":         "awsservicesquotafetcher/" + report.AccountID,
+		"severity":       severity,
+		"component":      q.ServiceName,
+		"group":          q.Region,
+		"class":          "aws-service-quota",
+		"timestamp":      report.GeneratedAt.UTC().Format(time.RFC3339),
+		"custom_details": quotaDetails(report, q),
+	}))
+}
+
+func (p *PagerDutyEvents) Resolve(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	return postJSON(ctx, p.Client, p.URL, nil, p.event("resolve", key, nil))
+}
+
+// OpsgenieAlerts sends alerts through the Opsgenie Alert API, using the quota key as alias
+type OpsgenieAlerts struct {
+	APIKey string
+	URL    string
+	Client *http.Client
+}
+
+func (o *OpsgenieAlerts) headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + o.APIKey}
+}
+
+func (o *OpsgenieAlerts) Trigger(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	priority := "P3"
+	if report.Severity(q) == SeverityCritical {
+		priority = "P1"
+	}
+	return postJSON(ctx, o.Client, o.URL, o.headers(), map[string]interface{}{
+		"message":     truncate(incidentSummary(report, q), 130),
+		"alias":       key,
+		"description": incidentSummary(report, q),
+		"priority":    priority,
+		"source":      "awsservicesquotafetcher",
+		"entity":      q.ServiceName,
+		"tags":        []string{"aws-service-quota", q.ServiceName, q.Region},
+		"details":     quotaDetails(report, q),
+	})
+}
+
+func (o *OpsgenieAlerts) Resolve(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	target := fmt.Sprintf("%s/%s/close?identifierType=alias", strings.TrimSuffix(o.URL, "/"), url.PathEscape(key))
+	return postJSON(ctx, o.Client, target, o.headers(), map[string]interface{}{
+		"source": "awsservicesquotafetcher",
+		"note":   fmt.Sprintf("Utilization dropped to %.2f%%", q.UtilizedPerc),
+	})
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeIncidents records the keys it was asked to trigger and resolve
+type fakeIncidents struct {
+	triggered, resolved []string
+}
+
+func (f *fakeIncidents) Trigger(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	f.triggered = append(f.triggered, key)
+	return nil
+}
+
+func (f *fakeIncidents) Resolve(ctx context.Context, report *Report, q QuotaInfo, key string) error {
+	f.resolved = append(f.resolved, key)
+	return nil
+}
+
+func TestIncidentKeptWhenQuotaFilteredOut(t *testing.T) {
+	state, err := LoadAlertState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	breached := QuotaInfo{ServiceName: "Amazon EC2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 100, Used: 95, UtilizedPerc: 95}
+	newReport := func(quotas []QuotaInfo) *Report {
+		return &Report{AccountID: "123456789012", GeneratedAt: time.Now(), Thresholds: Thresholds{Warning: 80, Critical: 90}, Quotas: quotas}
+	}
+	// Like main, a tracked run without changes has an empty, non-nil event list
+	evaluate := func(report *Report, fetched []QuotaInfo) {
+		report.Events = append([]AlertEvent{}, state.Evaluate(report, fetched, 0, 0)...)
+	}
+
+	api := &fakeIncidents{}
+	n := &IncidentNotifier{Label: "fake", API: api, MinSeverity: SeverityCritical}
+
+	first := newReport([]QuotaInfo{breached})
+	evaluate(first, first.Quotas)
+	if err := n.Notify(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+	key := quotaKey(first, breached)
+	if len(api.triggered) != 1 || api.triggered[0] != key {
+		t.Fatalf("triggered %v, want [%s]", api.triggered, key)
+	}
+
+	// Still at 95%, but a filter now drops it from the report: the incident stays open
+	second := newReport(nil)
+	evaluate(second, []QuotaInfo{breached})
+	if err := n.Notify(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+	if len(second.Events) != 0 || len(api.resolved) != 0 {
+		t.Fatalf("events %v, resolved %v for a filtered out breach", second.Events, api.resolved)
+	}
+	if record := state.Alerts[key]; record == nil || record.Severity != SeverityCritical || record.Runs != 1 {
+		t.Fatalf("alert state = %+v, want the critical record unchanged", record)
+	}
+
+	// Back in the report it does not trigger again
+	third := newReport([]QuotaInfo{breached})
+	evaluate(third, third.Quotas)
+	if err := n.Notify(context.Background(), third); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.triggered) != 1 {
+		t.Errorf("triggered %v, want one trigger", api.triggered)
+	}
+
+	// A failed fetch leaves the quota out of both lists and must not resolve anything
+	fourth := newReport(nil)
+	evaluate(fourth, nil)
+	if err := n.Notify(context.Background(), fourth); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.resolved) != 0 {
+		t.Errorf("resolved %v after a failed fetch", api.resolved)
+	}
+
+	// Filtered out once it is back under the thresholds, it recovers
+	recovered := breached
+	recovered.Used, recovered.UtilizedPerc = 10, 10
+	fifth := newReport(nil)
+	evaluate(fifth, []QuotaInfo{recovered})
+	if err := n.Notify(context.Background(), fifth); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.resolved) != 1 || api.resolved[0] != key {
+		t.Fatalf("resolved %v, want [%s]", api.resolved, key)
+	}
+	if _, ok := state.Alerts[key]; ok {
+		t.Error("alert state kept after recovery")
+	}
+}
+
+// incidentRequest is one request a fake PagerDuty or Opsgenie received
+type incidentRequest struct {
+	URI, Auth string
+	Body      map[string]interface{}
+}
+
+func newIncidentServer(t *testing.T) (*httptest.Server, *[]incidentRequest) {
+	t.Helper()
+	var requests []incidentRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := incidentRequest{URI: r.RequestURI, Auth: r.Header.Get("Authorization")}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s %s with Content-Type %q", r.Method, r.RequestURI, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req.Body); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestPagerDutyEventsPayload(t *testing.T) {
+	server, requests := newIncidentServer(t)
+	pd := &PagerDutyEvents{RoutingKey: "R0UT1NG", URL: server.URL + "/v2/enqueue", Client: server.Client()}
+	report := newTestReport(1)
+	q := report.Quotas[0]
+	q.Used, q.UtilizedPerc = 95, 95
+	key := quotaKey(report, q)
+
+	if err := pd.Trigger(context.Background(), report, q, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := pd.Resolve(context.Background(), report, q, key); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+
+	trigger, resolve := (*requests)[0], (*requests)[1]
+	for i, want := range []string{"trigger", "resolve"} {
+		body := (*requests)[i].Body
+		if (*requests)[i].URI != "/v2/enqueue" || body["routing_key"] != "R0UT1NG" || body["dedup_key"] != key || body["event_action"] != want {
+			t.Errorf("%s request = %s %v", want, (*requests)[i].URI, body)
+		}
+	}
+	payload, ok := trigger.Body["payload"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("trigger has no payload: %v", trigger.Body)
+	}
+	if payload["severity"] != "critical" || payload["component"] != q.ServiceName || payload["group"] != "us-east-1" {
+		t.Errorf("payload = %v", payload)
+	}
+	details, ok := payload["custom_details"].(map[string]interface{})
+	if !ok || details["account"] != "123456789012" || details["quota_code"] != q.QuotaCode || details["utilization"] != "95.00%" || details["severity"] != "critical" {
+		t.Errorf("custom_details = %v", payload["custom_details"])
+	}
+	if _, ok := resolve.Body["payload"]; ok {
+		t.Errorf("resolve carries a payload: %v", resolve.Body)
+	}
+}
+
+func TestOpsgenieAlertsRequests(t *testing.T) {
+	server, requests := newIncidentServer(t)
+	og := &OpsgenieAlerts{APIKey: "genie", URL: server.URL + "/v2/alerts/", Client: server.Client()}
+	report := newTestReport(1)
+	q := report.Quotas[0]
+	key := quotaKey(report, q)
+
+	if err := og.Trigger(context.Background(), report, q, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := og.Resolve(context.Background(), report, q, key); err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(*requests))
+	}
+	create, closeReq := (*requests)[0], (*requests)[1]
+	for _, req := range *requests {
+		if req.Auth != "GenieKey genie" {
+			t.Errorf("Authorization = %q", req.Auth)
+		}
+	}
+
+	if create.URI != "/v2/alerts/" {
+		t.Errorf("create URI = %q", create.URI)
+	}
+	if create.Body["alias"] != key || create.Body["priority"] != "P3" || create.Body["entity"] != q.ServiceName {
+		t.Errorf("create body = %v", create.Body)
+	}
+	if details, ok := create.Body["details"].(map[string]interface{}); !ok || details["quota_code"] != q.QuotaCode {
+		t.Errorf("details = %v", create.Body["details"])
+	}
+
+	// The alias contains slashes, which must stay inside one path segment
+	if want := "/v2/alerts/123456789012%2Fus-east-1%2FService%200%2FL-00000000/close?identifierType=alias"; closeReq.URI != want {
+		t.Errorf("close URI = %q, want %q", closeReq.URI, want)
+	}
+	if closeReq.Body["source"] != "awsservicesquotafetcher" {
+		t.Errorf("close body = %v", closeReq.Body)
+	}
+}
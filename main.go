@@ -1,17 +1,16 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/csv"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -48,6 +47,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
 	"github.com/aws/aws-sdk-go-v2/service/ses"
 	"github.com/aws/aws-sdk-go-v2/service/sfn"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
@@ -62,29 +62,142 @@ const (
 // QuotaInfo stores service quota details
 type QuotaInfo struct {
 	ServiceName  string
+	QuotaCode    string
 	QuotaName    string
 	Region       string
 	Allocated    float64
 	Used         float64
 	UtilizedPerc float64
+	Adjustable   bool
+	Global       bool
 }
 
-// FetchServiceQuotas retrieves quota info for a given AWS service
-func FetchServiceQuotas(ctx context.Context, cfg aws.Config, serviceCode string, region string) ([]QuotaInfo, error) {
-	sqClient := servicequotas.NewFromConfig(cfg)
+// quotaDefinition is the cacheable part of a quota: everything except usage
+type quotaDefinition struct {
+	ServiceCode  string       `json:"service_code"`
+	ServiceName  string       `json:"service_name"`
+	QuotaCode    string       `json:"quota_code"`
+	QuotaName    string       `json:"quota_name"`
+	Unit         string       `json:"unit,omitempty"`
+	Adjustable   bool         `json:"adjustable"`
+	Global       bool         `json:"global"`
+	DefaultValue *float64     `json:"default_value,omitempty"`
+	AppliedValue *float64     `json:"applied_value,omitempty"`
+	UsageMetric  *usageMetric `json:"usage_metric,omitempty"`
+}
+
+// usageMetric is the CloudWatch metric AWS publishes usage for a quota under
+type usageMetric struct {
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+	Statistic  string            `json:"statistic,omitempty"`
+}
+
+// Value returns the applied value, falling back to the AWS default
+func (d quotaDefinition) Value() float64 {
+	if d.AppliedValue != nil {
+		return *d.AppliedValue
+	}
+	if d.DefaultValue != nil {
+		return *d.DefaultValue
+	}
+	return 0
+}
 
-	// Fetch allocated quotas using Service Quotas API
-	sqOutput, err := sqClient.ListServiceQuotas(ctx, &servicequotas.ListServiceQuotasInput{
+func newQuotaDefinition(quota types.ServiceQuota) quotaDefinition {
+	def := quotaDefinition{
+		ServiceCode: aws.ToString(quota.ServiceCode),
+		ServiceName: aws.ToString(quota.ServiceName),
+		QuotaCode:   aws.ToString(quota.QuotaCode),
+		QuotaName:   aws.ToString(quota.QuotaName),
+		Unit:        aws.ToString(quota.Unit),
+		Adjustable:  quota.Adjustable,
+		Global:      quota.GlobalQuota,
+	}
+	if m := quota.UsageMetric; m != nil && m.MetricName != nil {
+		def.UsageMetric = &usageMetric{
+			Namespace:  aws.ToString(m.MetricNamespace),
+			Name:       aws.ToString(m.MetricName),
+			Dimensions: m.MetricDimensions,
+			Statistic:  aws.ToString(m.MetricStatisticRecommendation),
+		}
+	}
+	return def
+}
+
+// listQuotaDefinitions returns every quota of a service with its default and applied values.
+// Applied quotas keep API order; quotas only known by their default are appended.
+func listQuotaDefinitions(ctx context.Context, cfg aws.Config, serviceCode string, cache *QuotaCache) ([]quotaDefinition, error) {
+	var defs []quotaDefinition
+	if cache.get(ctx, cfg, "quotas/"+serviceCode, &defs) {
+		return defs, nil
+	}
+
+	sqClient := newServiceQuotasClient(cfg)
+
+	defaults := map[string]types.ServiceQuota{}
+	var defaultOrder []string
+	defaultPages := servicequotas.NewListAWSDefaultServiceQuotasPaginator(sqClient, &servicequotas.ListAWSDefaultServiceQuotasInput{
 		ServiceCode: aws.String(serviceCode),
 	})
+	for defaultPages.HasMorePages() {
+		page, err := defaultPages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching default quotas for %s: %v", serviceCode, err)
+		}
+		for _, quota := range page.Quotas {
+			code := aws.ToString(quota.QuotaCode)
+			defaults[code] = quota
+			defaultOrder = append(defaultOrder, code)
+		}
+	}
+
+	applied := map[string]bool{}
+	appliedPages := servicequotas.NewListServiceQuotasPaginator(sqClient, &servicequotas.ListServiceQuotasInput{
+		ServiceCode: aws.String(serviceCode),
+	})
+	for appliedPages.HasMorePages() {
+		page, err := appliedPages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching quotas for %s: %v", serviceCode, err)
+		}
+		for _, quota := range page.Quotas {
+			def := newQuotaDefinition(quota)
+			def.AppliedValue = quota.Value
+			if d, ok := defaults[def.QuotaCode]; ok {
+				def.DefaultValue = d.Value
+			}
+			applied[def.QuotaCode] = true
+			defs = append(defs, def)
+		}
+	}
+
+	for _, code := range defaultOrder {
+		if applied[code] {
+			continue
+		}
+		def := newQuotaDefinition(defaults[code])
+		def.DefaultValue = defaults[code].Value
+		defs = append(defs, def)
+	}
+
+	cache.put(ctx, cfg, "quotas/"+serviceCode, defs)
+	return defs, nil
+}
+
+// FetchServiceQuotas retrieves quota info for a given AWS service.
+// Quota metadata may come from cache; usage is always fetched fresh.
+func FetchServiceQuotas(ctx context.Context, cfg aws.Config, serviceCode string, region string, cache *QuotaCache) ([]QuotaInfo, error) {
+	defs, err := listQuotaDefinitions(ctx, cfg, serviceCode, cache)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching quotas for %s: %v", serviceCode, err)
+		return nil, err
 	}
 
 	var quotas []QuotaInfo
-	for _, quota := range sqOutput.Quotas {
-		allocated := *quota.Value
-		used := fetchUsedQuota(ctx, cfg, serviceCode, quota.QuotaName)
+	for _, def := range defs {
+		allocated := def.Value()
+		used := fetchUsedQuota(ctx, cfg, serviceCode, &def.QuotaName)
 
 		utilized := 0.0
 		if allocated > 0 {
@@ -93,30 +206,59 @@ func FetchServiceQuotas(ctx context.Context, cfg aws.Config, serviceCode string,
 
 		quotas = append(quotas, QuotaInfo{
 			ServiceName:  serviceCode,
-			QuotaName:    *quota.QuotaName,
+			QuotaCode:    def.QuotaCode,
+			QuotaName:    def.QuotaName,
 			Region:       region,
 			Allocated:    allocated,
 			Used:         used,
 			UtilizedPerc: utilized,
+			Adjustable:   def.Adjustable,
+			Global:       def.Global,
 		})
 	}
 
 	return quotas, nil
 }
 
-// Fetch actual usage based on service
-func fetchUsedQuota(ctx context.Context, cfg aws.Config, serviceCode string, quotaName *string) float64 {
-	switch serviceCode {
-	case "rds":
-		if *quotaName == "Parameter groups" {
+// collectQuotas fetches every service in every region; failures are logged and skipped
+func collectQuotas(ctx context.Context, cfg aws.Config, services []string, regions []string, cache *QuotaCache) ([]QuotaInfo, []FetchError) {
+	var allQuotas []QuotaInfo
+	var fetchErrors []FetchError
+	for _, service := range services {
+		for _, region := range regions {
+			cfg.Region = region
+			log.Printf("🔍 Fetching quotas for %s in region: %s", service, region)
+
+			quotas, err := FetchServiceQuotas(ctx, cfg, service, region, cache)
+			if err != nil {
+				log.Printf("❌ Error fetching quotas for %s: %v", service, err)
+				fetchErrors = append(fetchErrors, FetchError{Service: service, Region: region, Message: err.Error()})
+				continue
+			}
+			allQuotas = append(allQuotas, quotas...)
+		}
+	}
+	return allQuotas, fetchErrors
+}
+
+// usageCollector counts what a quota currently uses
+type usageCollector func(ctx context.Context, cfg aws.Config) float64
+
+// usageCollectors maps Service Quotas service codes (as --list-services prints them) and quota
+// names to usage collectors; it drives both fetchUsedQuota and the --list-services annotation
+var usageCollectors = map[string]map[string]usageCollector{
+	"rds": {
+		"Parameter groups": func(ctx context.Context, cfg aws.Config) float64 {
 			rdsClient := rds.NewFromConfig(cfg)
 			output, err := rdsClient.DescribeDBParameterGroups(ctx, &rds.DescribeDBParameterGroupsInput{})
 			if err == nil {
 				return float64(len(output.DBParameterGroups))
 			}
-		}
-	case "ec2":
-		if *quotaName == "Running On-Demand instances" {
+			return 0
+		},
+	},
+	"ec2": {
+		"Running On-Demand instances": func(ctx context.Context, cfg aws.Config) float64 {
 			ec2Client := ec2.NewFromConfig(cfg)
 			output, err := ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{})
 			if err == nil {
@@ -126,105 +268,139 @@ func fetchUsedQuota(ctx context.Context, cfg aws.Config, serviceCode string, quo
 				}
 				return float64(count)
 			}
-		}
-	case "s3":
-		if *quotaName == "Total Buckets" {
+			return 0
+		},
+		"Elastic IPs": func(ctx context.Context, cfg aws.Config) float64 {
+			ec2Client := ec2.NewFromConfig(cfg)
+			output, err := ec2Client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+			if err == nil {
+				return float64(len(output.Addresses))
+			}
+			return 0
+		},
+	},
+	"s3": {
+		"Total Buckets": func(ctx context.Context, cfg aws.Config) float64 {
 			s3Client := s3.NewFromConfig(cfg)
 			output, err := s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
 			if err == nil {
 				return float64(len(output.Buckets))
 			}
-		}
-	case "vpc":
-		if *quotaName == "VPCs per Region" {
+			return 0
+		},
+	},
+	"vpc": {
+		"VPCs per Region": func(ctx context.Context, cfg aws.Config) float64 {
 			vpcClient := ec2.NewFromConfig(cfg)
 			output, err := vpcClient.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{})
 			if err == nil {
 				return float64(len(output.Vpcs))
 			}
-		}
-	case "route53":
-		if *quotaName == "Hosted Zones" {
+			return 0
+		},
+	},
+	"route53": {
+		"Hosted Zones": func(ctx context.Context, cfg aws.Config) float64 {
 			route53Client := route53.NewFromConfig(cfg)
 			output, err := route53Client.ListHostedZones(ctx, &route53.ListHostedZonesInput{})
 			if err == nil {
 				return float64(len(output.HostedZones))
 			}
-		}
-	case "elasticloadbalancing":
-		if *quotaName == "Load Balancers" {
+			return 0
+		},
+	},
+	"elasticloadbalancing": {
+		"Load Balancers": func(ctx context.Context, cfg aws.Config) float64 {
 			elbClient := elasticloadbalancing.NewFromConfig(cfg)
 			output, err := elbClient.DescribeLoadBalancers(ctx, &elasticloadbalancing.DescribeLoadBalancersInput{})
 			if err == nil {
 				return float64(len(output.LoadBalancerDescriptions))
 			}
-		}
-	case "autoscaling":
-		if *quotaName == "Auto Scaling Groups" {
+			return 0
+		},
+	},
+	"autoscaling": {
+		"Auto Scaling Groups": func(ctx context.Context, cfg aws.Config) float64 {
 			asgClient := autoscaling.NewFromConfig(cfg)
 			output, err := asgClient.DescribeAutoScalingGroups(ctx, &autoscaling.DescribeAutoScalingGroupsInput{})
 			if err == nil {
 				return float64(len(output.AutoScalingGroups))
 			}
-		}
-	case "inspector":
-		if *quotaName == "Assessment Templates" {
+			return 0
+		},
+	},
+	"inspector": {
+		"Assessment Templates": func(ctx context.Context, cfg aws.Config) float64 {
 			inspectorClient := inspector.NewFromConfig(cfg)
 			output, err := inspectorClient.ListAssessmentTemplates(ctx, &inspector.ListAssessmentTemplatesInput{})
 			if err == nil {
 				return float64(len(output.AssessmentTemplateArns))
 			}
-		}
-	case "apigateway":
-		if *quotaName == "APIs" {
+			return 0
+		},
+	},
+	"apigateway": {
+		"APIs": func(ctx context.Context, cfg aws.Config) float64 {
 			apigatewayClient := apigateway.NewFromConfig(cfg)
 			output, err := apigatewayClient.GetRestApis(ctx, &apigateway.GetRestApisInput{})
 			if err == nil {
 				return float64(len(output.Items))
 			}
-		}
-	case "dynamodb":
-		if *quotaName == "Tables" {
+			return 0
+		},
+	},
+	"dynamodb": {
+		"Tables": func(ctx context.Context, cfg aws.Config) float64 {
 			dynamodbClient := dynamodb.NewFromConfig(cfg)
 			output, err := dynamodbClient.ListTables(ctx, &dynamodb.ListTablesInput{})
 			if err == nil {
 				return float64(len(output.TableNames))
 			}
-		}
-	case "ebs":
-		if *quotaName == "Volumes" {
+			return 0
+		},
+	},
+	"ebs": {
+		"Volumes": func(ctx context.Context, cfg aws.Config) float64 {
 			ebsClient := ec2.NewFromConfig(cfg)
 			output, err := ebsClient.DescribeVolumes(ctx, &ec2.DescribeVolumesInput{})
 			if err == nil {
 				return float64(len(output.Volumes))
 			}
-		}
-	case "efs":
-		if *quotaName == "File Systems" {
+			return 0
+		},
+	},
+	"elasticfilesystem": {
+		"File Systems": func(ctx context.Context, cfg aws.Config) float64 {
 			efsClient := efs.NewFromConfig(cfg)
 			output, err := efsClient.DescribeFileSystems(ctx, &efs.DescribeFileSystemsInput{})
 			if err == nil {
 				return float64(len(output.FileSystems))
 			}
-		}
-	case "ecr":
-		if *quotaName == "Repositories" {
+			return 0
+		},
+	},
+	"ecr": {
+		"Repositories": func(ctx context.Context, cfg aws.Config) float64 {
 			ecrClient := ecr.NewFromConfig(cfg)
 			output, err := ecrClient.DescribeRepositories(ctx, &ecr.DescribeRepositoriesInput{})
 			if err == nil {
 				return float64(len(output.Repositories))
 			}
-		}
-	case "eks":
-		if *quotaName == "Clusters" {
+			return 0
+		},
+	},
+	"eks": {
+		"Clusters": func(ctx context.Context, cfg aws.Config) float64 {
 			eksClient := eks.NewFromConfig(cfg)
 			output, err := eksClient.ListClusters(ctx, &eks.ListClustersInput{})
 			if err == nil {
 				return float64(len(output.Clusters))
 			}
-		}
-	case "ses":
-		if *quotaName == "Verified Email Addresses" {
+			return 0
+		},
+	},
+	"ses": {
+		"Verified Email Addresses": func(ctx context.Context, cfg aws.Config) float64 {
 			sesClient := ses.NewFromConfig(cfg)
 			output, err := sesClient.ListIdentities(ctx, &ses.ListIdentitiesInput{
 				IdentityType: "EmailAddress",
@@ -232,223 +408,264 @@ func fetchUsedQuota(ctx context.Context, cfg aws.Config, serviceCode string, quo
 			if err == nil {
 				return float64(len(output.Identities))
 			}
-		}
-	case "sns":
-		if *quotaName == "Topics" {
+			return 0
+		},
+	},
+	"sns": {
+		"Topics": func(ctx context.Context, cfg aws.Config) float64 {
 			snsClient := sns.NewFromConfig(cfg)
 			output, err := snsClient.ListTopics(ctx, &sns.ListTopicsInput{})
 			if err == nil {
 				return float64(len(output.Topics))
 			}
-		}
-	case "acm":
-		if *quotaName == "Certificates" {
+			return 0
+		},
+	},
+	"acm": {
+		"Certificates": func(ctx context.Context, cfg aws.Config) float64 {
 			acmClient := acm.NewFromConfig(cfg)
 			output, err := acmClient.ListCertificates(ctx, &acm.ListCertificatesInput{})
 			if err == nil {
 				return float64(len(output.CertificateSummaryList))
 			}
-		}
-	case "secretsmanager":
-		if *quotaName == "Secrets" {
+			return 0
+		},
+	},
+	"secretsmanager": {
+		"Secrets": func(ctx context.Context, cfg aws.Config) float64 {
 			secretsManagerClient := secretsmanager.NewFromConfig(cfg)
 			output, err := secretsManagerClient.ListSecrets(ctx, &secretsmanager.ListSecretsInput{})
 			if err == nil {
 				return float64(len(output.SecretList))
 			}
-		}
-	case "elasticip":
-		if *quotaName == "Elastic IPs" {
-			ec2Client := ec2.NewFromConfig(cfg)
-			output, err := ec2Client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
-			if err == nil {
-				return float64(len(output.Addresses))
-			}
-		}
-	case "backup":
-		if *quotaName == "Backup Plans" {
+			return 0
+		},
+	},
+	"backup": {
+		"Backup Plans": func(ctx context.Context, cfg aws.Config) float64 {
 			backupClient := backup.NewFromConfig(cfg)
 			output, err := backupClient.ListBackupPlans(ctx, &backup.ListBackupPlansInput{})
 			if err == nil {
 				return float64(len(output.BackupPlansList))
 			}
-		}
-	case "sqs":
-		if *quotaName == "Queues" {
+			return 0
+		},
+	},
+	"sqs": {
+		"Queues": func(ctx context.Context, cfg aws.Config) float64 {
 			sqsClient := sqs.NewFromConfig(cfg)
 			output, err := sqsClient.ListQueues(ctx, &sqs.ListQueuesInput{})
 			if err == nil {
 				return float64(len(output.QueueUrls))
 			}
-		}
-	case "kms":
-		if *quotaName == "Keys" {
+			return 0
+		},
+	},
+	"kms": {
+		"Keys": func(ctx context.Context, cfg aws.Config) float64 {
 			kmsClient := kms.NewFromConfig(cfg)
 			output, err := kmsClient.ListKeys(ctx, &kms.ListKeysInput{})
 			if err == nil {
 				return float64(len(output.Keys))
 			}
-		}
-	case "iam":
-		if *quotaName == "Users" {
+			return 0
+		},
+	},
+	"iam": {
+		"Users": func(ctx context.Context, cfg aws.Config) float64 {
 			iamClient := iam.NewFromConfig(cfg)
 			output, err := iamClient.ListUsers(ctx, &iam.ListUsersInput{})
 			if err == nil {
 				return float64(len(output.Users))
 			}
-		}
-	case "lambda":
-		if *quotaName == "Functions" {
+			return 0
+		},
+	},
+	"lambda": {
+		"Functions": func(ctx context.Context, cfg aws.Config) float64 {
 			lambdaClient := lambda.NewFromConfig(cfg)
 			output, err := lambdaClient.ListFunctions(ctx, &lambda.ListFunctionsInput{})
 			if err == nil {
 				return float64(len(output.Functions))
 			}
-		}
-	case "redshift":
-		if *quotaName == "Clusters" {
+			return 0
+		},
+	},
+	"redshift": {
+		"Clusters": func(ctx context.Context, cfg aws.Config) float64 {
 			redshiftClient := redshift.NewFromConfig(cfg)
 			output, err := redshiftClient.DescribeClusters(ctx, &redshift.DescribeClustersInput{})
 			if err == nil {
 				return float64(len(output.Clusters))
 			}
-		}
-	case "cloudfront":
-		if *quotaName == "Distributions" {
+			return 0
+		},
+	},
+	"cloudfront": {
+		"Distributions": func(ctx context.Context, cfg aws.Config) float64 {
 			cloudfrontClient := cloudfront.NewFromConfig(cfg)
 			output, err := cloudfrontClient.ListDistributions(ctx, &cloudfront.ListDistributionsInput{})
 			if err == nil {
 				return float64(len(output.DistributionList.Items))
 			}
-		}
-	case "cloudwatch":
-		if *quotaName == "Alarms" {
+			return 0
+		},
+	},
+	"monitoring": {
+		"Alarms": func(ctx context.Context, cfg aws.Config) float64 {
 			cloudwatchClient := cloudwatch.NewFromConfig(cfg)
 			output, err := cloudwatchClient.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{})
 			if err == nil {
 				return float64(len(output.MetricAlarms))
 			}
-		}
-	case "opensearch":
-		if *quotaName == "Domains" {
+			return 0
+		},
+	},
+	"es": {
+		"Domains": func(ctx context.Context, cfg aws.Config) float64 {
 			opensearchClient := opensearch.NewFromConfig(cfg)
 			output, err := opensearchClient.ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
 			if err == nil {
 				return float64(len(output.DomainNames))
 			}
-		}
-	case "glacier":
-		if *quotaName == "Vaults" {
+			return 0
+		},
+	},
+	"glacier": {
+		"Vaults": func(ctx context.Context, cfg aws.Config) float64 {
 			glacierClient := glacier.NewFromConfig(cfg)
 			output, err := glacierClient.ListVaults(ctx, &glacier.ListVaultsInput{})
 			if err == nil {
 				return float64(len(output.VaultList))
 			}
-		}
-	case "sagemaker":
-		if *quotaName == "Notebook Instances" {
+			return 0
+		},
+	},
+	"sagemaker": {
+		"Notebook Instances": func(ctx context.Context, cfg aws.Config) float64 {
 			sagemakerClient := sagemaker.NewFromConfig(cfg)
 			output, err := sagemakerClient.ListNotebookInstances(ctx, &sagemaker.ListNotebookInstancesInput{})
 			if err == nil {
 				return float64(len(output.NotebookInstances))
 			}
-		}
-	case "elasticache":
-		if *quotaName == "Clusters" {
+			return 0
+		},
+	},
+	"elasticache": {
+		"Clusters": func(ctx context.Context, cfg aws.Config) float64 {
 			elasticacheClient := elasticache.NewFromConfig(cfg)
 			output, err := elasticacheClient.DescribeCacheClusters(ctx, &elasticache.DescribeCacheClustersInput{})
 			if err == nil {
 				return float64(len(output.CacheClusters))
 			}
-		}
-	case "codebuild":
-		if *quotaName == "Projects" {
+			return 0
+		},
+	},
+	"codebuild": {
+		"Projects": func(ctx context.Context, cfg aws.Config) float64 {
 			codebuildClient := codebuild.NewFromConfig(cfg)
 			output, err := codebuildClient.ListProjects(ctx, &codebuild.ListProjectsInput{})
 			if err == nil {
 				return float64(len(output.Projects))
 			}
-		}
-	case "codepipeline":
-		if *quotaName == "Pipelines" {
+			return 0
+		},
+	},
+	"codepipeline": {
+		"Pipelines": func(ctx context.Context, cfg aws.Config) float64 {
 			codepipelineClient := codepipeline.NewFromConfig(cfg)
 			output, err := codepipelineClient.ListPipelines(ctx, &codepipeline.ListPipelinesInput{})
 			if err == nil {
 				return float64(len(output.Pipelines))
 			}
-		}
-	case "codedeploy":
-		if *quotaName == "Applications" {
+			return 0
+		},
+	},
+	"codedeploy": {
+		"Applications": func(ctx context.Context, cfg aws.Config) float64 {
 			codedeployClient := codedeploy.NewFromConfig(cfg)
 			output, err := codedeployClient.ListApplications(ctx, &codedeploy.ListApplicationsInput{})
 			if err == nil {
 				return float64(len(output.Applications))
 			}
-		}
-	case "glue":
-		if *quotaName == "Jobs" {
+			return 0
+		},
+	},
+	"glue": {
+		"Jobs": func(ctx context.Context, cfg aws.Config) float64 {
 			glueClient := glue.NewFromConfig(cfg)
 			output, err := glueClient.GetJobs(ctx, &glue.GetJobsInput{})
 			if err == nil {
 				return float64(len(output.Jobs))
 			}
-		}
-	case "athena":
-		if *quotaName == "Workgroups" {
+			return 0
+		},
+	},
+	"athena": {
+		"Workgroups": func(ctx context.Context, cfg aws.Config) float64 {
 			athenaClient := athena.NewFromConfig(cfg)
 			output, err := athenaClient.ListWorkGroups(ctx, &athena.ListWorkGroupsInput{})
 			if err == nil {
 				return float64(len(output.WorkGroups))
 			}
-		}
-	case "stepfunctions":
-		if *quotaName == "State Machines" {
+			return 0
+		},
+	},
+	"states": {
+		"State Machines": func(ctx context.Context, cfg aws.Config) float64 {
 			stepfunctionsClient := sfn.NewFromConfig(cfg)
 			output, err := stepfunctionsClient.ListStateMachines(ctx, &sfn.ListStateMachinesInput{})
 			if err == nil {
 				return float64(len(output.StateMachines))
 			}
-		}
-	case "appmesh":
-		if *quotaName == "Meshes" {
+			return 0
+		},
+	},
+	"appmesh": {
+		"Meshes": func(ctx context.Context, cfg aws.Config) float64 {
 			appmeshClient := appmesh.NewFromConfig(cfg)
 			output, err := appmeshClient.ListMeshes(ctx, &appmesh.ListMeshesInput{})
 			if err == nil {
 				return float64(len(output.Meshes))
 			}
-		}
-	case "timestream":
-		if *quotaName == "Databases" {
+			return 0
+		},
+	},
+	"timestream": {
+		"Databases": func(ctx context.Context, cfg aws.Config) float64 {
 			timestreamClient := timestreamwrite.NewFromConfig(cfg)
 			output, err := timestreamClient.ListDatabases(ctx, &timestreamwrite.ListDatabasesInput{})
 			if err == nil {
 				return float64(len(output.Databases))
 			}
-		}
-	case "fsx":
-		if *quotaName == "File Systems" {
+			return 0
+		},
+	},
+	"fsx": {
+		"File Systems": func(ctx context.Context, cfg aws.Config) float64 {
 			fsxClient := fsx.NewFromConfig(cfg)
 			output, err := fsxClient.DescribeFileSystems(ctx, &fsx.DescribeFileSystemsInput{})
 			if err == nil {
 				return float64(len(output.FileSystems))
 			}
-		}
+			return 0
+		},
+	},
+}
+
+// Fetch actual usage based on service
+func fetchUsedQuota(ctx context.Context, cfg aws.Config, serviceCode string, quotaName *string) float64 {
+	if collect, ok := usageCollectors[serviceCode][*quotaName]; ok {
+		return collect(ctx, cfg)
 	}
 
 	// Default case returns 0.0
 	return 0.0
 }
 
-// Save results to CSV
-func SaveToCSV(quotas []QuotaInfo, outputPath string) error {
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// writeCSV writes quotas in the CSV layout used by --output
+func writeCSV(w io.Writer, quotas []QuotaInfo) error {
+	writer := csv.NewWriter(w)
 
 	writer.Write([]string{"Service Name", "Quota Name", "Region", "Allocated Quota", "Used Quota", "Utilized (%)"})
 
@@ -463,178 +680,187 @@ func SaveToCSV(quotas []QuotaInfo, outputPath string) error {
 		})
 	}
 
-	log.Printf("✅ CSV file saved to %s", outputPath)
-	return nil
+	writer.Flush()
+	return writer.Error()
 }
 
-// List valid AWS services
-func listValidServices() {
-	validServiceCodes := map[string]string{
-		"ec2":            "Amazon Elastic Compute Cloud (EC2)",
-		"vpc":            "Amazon Virtual Private Cloud (VPC)",
-		"route53":        "Amazon Route 53",
-		"elb":            "Elastic Load Balancing (ELB)",
-		"autoscaling":    "Auto Scaling",
-		"inspector":      "Amazon Inspector",
-		"apigateway":     "Amazon API Gateway",
-		"dynamodb":       "Amazon DynamoDB",
-		"ebs":            "Amazon Elastic Block Store (EBS)",
-		"efs":            "Amazon Elastic File System (EFS)",
-		"ecr":            "Amazon Elastic Container Registry (ECR)",
-		"eks":            "Amazon Elastic Kubernetes Service (EKS)",
-		"ses":            "Amazon Simple Email Service (SES)",
-		"sns":            "Amazon Simple Notification Service (SNS)",
-		"acm":            "AWS Certificate Manager (ACM)",
-		"secretsmanager": "AWS Secrets Manager",
-		"elasticip":      "Elastic IP",
-		"backup":         "AWS Backup",
-		"sqs":            "Amazon Simple Queue Service (SQS)",
-		"kms":            "AWS Key Management Service (KMS)",
-		"iam":            "AWS Identity and Access Management (IAM)",
-		"lambda":         "AWS Lambda",
-		"rds":            "Amazon Relational Database Service (RDS)",
-		"redshift":       "Amazon Redshift",
-		"cloudfront":     "Amazon CloudFront",
-		"cloudwatch":     "Amazon CloudWatch",
-		"opensearch":     "Amazon OpenSearch Service",
-		"s3":             "Amazon Simple Storage Service (S3)",
-		"glacier":        "Amazon S3 Glacier",
-		"sagemaker":      "Amazon SageMaker",
-		"elasticache":    "Amazon ElastiCache",
-		"codebuild":      "AWS CodeBuild",
-		"codepipeline":   "AWS CodePipeline",
-		"codedeploy":     "AWS CodeDeploy",
-		"glue":           "AWS Glue",
-		"athena":         "Amazon Athena",
-		"stepfunctions":  "AWS Step Functions",
-		"msk":            "Amazon Managed Streaming for Apache Kafka (MSK)",
-		"appmesh":        "AWS App Mesh",
-		"timestream":     "Amazon Timestream",
-		"fsx":            "Amazon FSx",
-	}
-
-	fmt.Println("Valid AWS services:")
-	for code, name := range validServiceCodes {
-		fmt.Printf("  %s - %s\n", code, name)
-	}
-	os.Exit(0)
-}
-
-// List quotas for a specific service
-func listQuotasForService(serviceCode string, region string, profile string) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(region),
-		config.WithSharedConfigProfile(profile),
-	)
+// LoadCSV reads quotas back from a CSV file written by --output
+func LoadCSV(path string) ([]QuotaInfo, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		log.Fatalf("❌ AWS config error: %v", err)
-	}
-
-	svc := servicequotas.NewFromConfig(cfg)
-
-	input := &servicequotas.ListServiceQuotasInput{
-		ServiceCode: &serviceCode,
+		return nil, err
 	}
+	defer file.Close()
 
-	result, err := svc.ListServiceQuotas(context.TODO(), input)
+	records, err := csv.NewReader(file).ReadAll()
 	if err != nil {
-		log.Fatalf("❌ Error fetching quotas for %s: %v", serviceCode, err)
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
 	}
 
-	fmt.Printf("Available Quotas for %s in region %s:\n", serviceCode, region)
-	for _, quota := range result.Quotas {
-		fmt.Printf("  - %s (Quota Code: %s)\n", *quota.QuotaName, *quota.QuotaArn)
-	}
-	os.Exit(0)
-}
-
-// Push data to Slack
-func pushToSlack(url string, data interface{}, format string) error {
-	var payload []byte
-	var err error
-
-	if format == "json" {
-		payload, err = json.Marshal(data)
-		if err != nil {
-			return fmt.Errorf("❌ Error marshaling data to JSON: %v", err)
-		}
-	} else {
-		var buffer bytes.Buffer
-		for _, q := range data.([]QuotaInfo) {
-			buffer.WriteString(fmt.Sprintf("%s\t%s\t%s\t%.2f\t%.2f\t%.2f%%\n", q.ServiceName, q.QuotaName, q.Region, q.Allocated, q.Used, q.UtilizedPerc))
+	var quotas []QuotaInfo
+	for i, record := range records {
+		if i == 0 || len(record) < 6 {
+			continue // header or malformed row
 		}
-		payload = buffer.Bytes()
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
-	if err != nil {
-		return fmt.Errorf("❌ Error creating HTTP request: %v", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("❌ Error sending HTTP request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("❌ Received non-OK response from Slack: %s", resp.Status)
+		allocated, _ := strconv.ParseFloat(record[3], 64)
+		used, _ := strconv.ParseFloat(record[4], 64)
+		utilized, _ := strconv.ParseFloat(strings.TrimSuffix(record[5], "%"), 64)
+		quotas = append(quotas, QuotaInfo{
+			ServiceName:  record[0],
+			QuotaName:    record[1],
+			Region:       record[2],
+			Allocated:    allocated,
+			Used:         used,
+			UtilizedPerc: utilized,
+		})
 	}
-
-	return nil
+	return quotas, nil
 }
 
-func pushDataToSlack(url string, token string, data []QuotaInfo) error {
-	var buffer bytes.Buffer
-	buffer.WriteString("Service Name\tQuota Name\tRegion\tAllocated Quota\tUsed Quota\tUtilized (%)\n")
-	for _, q := range data {
-		buffer.WriteString(fmt.Sprintf("%s\t%s\t%s\t%.2f\t%.2f\t%.2f%%\n", q.ServiceName, q.QuotaName, q.Region, q.Allocated, q.Used, q.UtilizedPerc))
+// commandArgs collects command words, allowing flags before, between and after them
+func commandArgs() []string {
+	var words []string
+	for flag.NArg() > 0 {
+		words = append(words, flag.Arg(0))
+		flag.CommandLine.Parse(flag.Args()[1:])
 	}
+	return words
+}
 
-	payload := map[string]string{"text": buffer.String()}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("❌ Error marshaling payload: %v", err)
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
+// Load the AWS config for a profile and region
+func loadAWSConfig(profile string, region string) aws.Config {
+	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithSharedConfigProfile(profile),
+		config.WithRegion(region),
+	)
 	if err != nil {
-		return fmt.Errorf("❌ Error creating HTTP request: %v", err)
+		log.Fatalf("❌ Error loading AWS config: %v", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	return cfg
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// List quotas for a specific service
+func listQuotasForService(ctx context.Context, cfg aws.Config, serviceCode string, cache *QuotaCache) {
+	defs, err := listQuotaDefinitions(ctx, cfg, serviceCode, cache)
 	if err != nil {
-		return fmt.Errorf("❌ Error sending HTTP request: %v", err)
+		log.Fatalf("❌ Error fetching quotas for %s: %v", serviceCode, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("❌ Received non-OK response from Slack: %s", resp.Status)
+	fmt.Printf("Available Quotas for %s in region %s:\n", serviceCode, cfg.Region)
+	for _, def := range defs {
+		fmt.Printf("  - %s (Quota Code: %s)\n", def.QuotaName, def.QuotaCode)
 	}
-
-	return nil
+	os.Exit(0)
 }
 
 func main() {
-	servicesFlag := flag.String("services", "", "Comma-separated AWS services (e.g., rds,ec2)")
+	servicesFlag := flag.String("services", "", "Comma-separated AWS services (e.g., rds,ec2), or all")
 	regionsFlag := flag.String("regions", "us-east-1", "Comma-separated AWS regions")
 	profileFlag := flag.String("profile", "", "AWS profile name (required)")
-	outputFlag := flag.String("output", "", "CSV output file (optional)")
+	outputFlag := flag.String("output", "", "Output file (optional)")
+	templateFlag := flag.String("template", "", "Go template file to render the report with, to --output or stdout")
+	stepSummaryFlag := flag.Bool("github-step-summary", false, "Append a Markdown report to $GITHUB_STEP_SUMMARY")
+	outputFormatFlag := flag.String("output-format", "csv", "Format of --output and S3 reports (csv, json, parquet, html, markdown or xlsx)")
 	versionFlag := flag.Bool("version", false, "Display CLI version")
-	listServicesFlag := flag.Bool("list-services", false, "List valid AWS services")
+	listServicesFlag := flag.Bool("list-services", false, "List AWS services with quotas and whether usage is collected")
 	listQuotasFlag := flag.String("list-quotas", "", "List quotas for a service (e.g., --list-quotas ec2)")
-	slackURLFlag := flag.String("url-to-push", "", "Slack URL to push data")
-	formatFlag := flag.String("format", "table", "Format to push data (table or json)")
-	pushDataToSlackFlag := flag.String("push-data-to-slack", "", "Slack URL to push data")
-	slackTokenFlag := flag.String("slack-token", "", "Slack API token for authentication")
+	slackURLFlag := flag.String("url-to-push", "", "Slack incoming webhook URL to push a Block Kit report to")
+	slackMaxQuotasFlag := flag.Int("slack-max-quotas", 50, "Maximum breaching quotas listed in a Slack report (0 for all)")
+	warningThresholdFlag := flag.Float64("warning-threshold", 80, "Utilization (%) at which a quota is a warning")
+	criticalThresholdFlag := flag.Float64("critical-threshold", 90, "Utilization (%) at which a quota is critical")
+	slackTokenFlag := flag.String("slack-token", "", "Slack bot token for the Web API")
+	slackChannelFlag := flag.String("slack-channel", "", "Slack channel ID or name to post to with --slack-token")
+	pushDataToSlackFlag := flag.String("push-data-to-slack", "", "Deprecated: use --slack-channel with --slack-token")
+	slackFileFormatFlag := flag.String("slack-file-format", "csv", "Format of the full report uploaded to Slack (csv, json or none)")
+	teamsURLFlag := flag.String("teams-webhook-url", "", "Microsoft Teams Workflows or incoming webhook URL")
+	teamsMaxQuotasFlag := flag.Int("teams-max-quotas", 25, "Maximum breaching quotas listed in a Teams card (0 for all)")
+	pagerDutyKeyFlag := flag.String("pagerduty-routing-key", "", "PagerDuty Events API v2 routing key")
+	pagerDutyURLFlag := flag.String("pagerduty-url", pagerDutyDefaultURL, "PagerDuty Events API v2 endpoint")
+	opsgenieKeyFlag := flag.String("opsgenie-api-key", "", "Opsgenie API key")
+	opsgenieURLFlag := flag.String("opsgenie-url", opsgenieDefaultURL, "Opsgenie Alert API endpoint (use api.eu.opsgenie.com for EU accounts)")
+	incidentSeverityFlag := flag.String("incident-severity", "critical", "Lowest severity that opens a PagerDuty/Opsgenie alert (warning or critical)")
+	alertStateFlag := flag.String("alert-state-file", "", "File remembering alerted quotas so only changes are notified (default: notify every run)")
+	renotifyFlag := flag.Duration("renotify-interval", 24*time.Hour, "Re-notify quotas still breaching after this long (0 to never re-notify)")
+	smtpHostFlag := flag.String("smtp-host", "", "SMTP server for the email digest")
+	smtpPortFlag := flag.Int("smtp-port", 587, "SMTP server port")
+	smtpUserFlag := flag.String("smtp-username", "", "SMTP username (password from SMTP_PASSWORD)")
+	smtpStartTLSFlag := flag.Bool("smtp-starttls", true, "Require STARTTLS before sending")
+	emailFromFlag := flag.String("email-from", "", "Sender address for the email digest")
+	emailToFlag := flag.String("email-to", "", "Comma-separated recipients of every email digest")
+	emailToWarningFlag := flag.String("email-to-warning", "", "Comma-separated recipients when any quota is at warning or above")
+	emailToCriticalFlag := flag.String("email-to-critical", "", "Comma-separated recipients when any quota is critical")
+	emailBaselineFlag := flag.String("email-baseline", "", "Previous CSV report to show changes against (e.g., last week's)")
+	webhookTemplateFlag := flag.String("webhook-template", "", "Go text/template file defining a generic webhook request")
+	webhookURLFlag := flag.String("webhook-url", "", "Webhook URL when the template defines no \"url\"")
+	webhookRetriesFlag := flag.Int("webhook-retries", 3, "Retries for webhook requests that fail with 5xx or network errors")
+	jiraURLFlag := flag.String("jira-url", "", "Jira base URL for sustained-breach tickets (token from JIRA_API_TOKEN)")
+	jiraUserFlag := flag.String("jira-user", "", "Jira user (email for Jira Cloud)")
+	jiraProjectFlag := flag.String("jira-project", "", "Jira project key for sustained-breach tickets")
+	jiraIssueTypeFlag := flag.String("jira-issue-type", "Task", "Jira issue type for sustained-breach tickets")
+	jiraDoneFlag := flag.String("jira-done-transition", "Done", "Jira workflow transition that closes a recovered ticket")
+	githubRepoFlag := flag.String("github-repo", "", "GitHub owner/repo for sustained-breach issues (token from GITHUB_TOKEN)")
+	githubAPIURLFlag := flag.String("github-api-url", githubDefaultAPIURL, "GitHub API base URL")
+	ticketRunsFlag := flag.Int("ticket-after-runs", 3, "Consecutive breaching runs before a Jira ticket or GitHub issue is opened")
+	cloudWatchMetricsFlag := flag.Bool("cloudwatch-metrics", false, "Publish Allocated, Used and UtilizedPerc per quota as CloudWatch custom metrics")
+	cloudWatchNamespaceFlag := flag.String("cloudwatch-namespace", cloudWatchDefaultNamespace, "CloudWatch namespace for quota metrics")
+	cloudWatchRegionFlag := flag.String("cloudwatch-region", "", "Region to publish CloudWatch metrics to (default: first --regions entry)")
+	cloudWatchEndpointFlag := flag.String("cloudwatch-endpoint", "", "CloudWatch endpoint override (e.g., a local stand-in)")
+	otelEndpointFlag := flag.String("otel-endpoint", "", "OTLP endpoint (host:port or URL) to export quota gauges to")
+	otelProtocolFlag := flag.String("otel-protocol", "grpc", "OTLP protocol (grpc or http)")
+	otelInsecureFlag := flag.Bool("otel-insecure", false, "Use plaintext instead of TLS for OTLP")
+	otelIntervalFlag := flag.Duration("otel-interval", 5*time.Minute, "How often serve refetches quotas and exports them over OTLP")
+	metricsPrefixFlag := flag.String("metrics-prefix", defaultMetricsPrefix, "Metric name prefix for StatsD, Datadog, InfluxDB and OTLP")
+	statsdAddrFlag := flag.String("statsd-addr", "", "StatsD host:port to send gauges to over UDP")
+	dogstatsdFlag := flag.Bool("dogstatsd", false, "Send DogStatsD tags instead of encoding them in StatsD metric names")
+	datadogFlag := flag.Bool("datadog-metrics", false, "Submit metrics to the Datadog API (key from DD_API_KEY)")
+	datadogURLFlag := flag.String("datadog-url", datadogDefaultURL, "Datadog API base URL for your site")
+	influxURLFlag := flag.String("influxdb-url", "", "InfluxDB v2 URL to write line protocol to (token from INFLUX_TOKEN)")
+	influxOrgFlag := flag.String("influxdb-org", "", "InfluxDB organization")
+	influxBucketFlag := flag.String("influxdb-bucket", "", "InfluxDB bucket")
+	s3BucketFlag := flag.String("s3-bucket", "", "S3 bucket to upload each run's report to")
+	s3PrefixFlag := flag.String("s3-prefix", "quota-reports", "Key prefix for S3 reports")
+	s3KMSKeyFlag := flag.String("s3-kms-key-id", "", "KMS key ID or ARN for SSE-KMS encryption of S3 reports")
+	s3EndpointFlag := flag.String("s3-endpoint", "", "S3 endpoint override (e.g., a local stand-in)")
+	alarmThresholdFlag := flag.Float64("alarm-threshold", 80, "Utilization (%) at which generated CloudWatch alarms fire")
+	alarmSNSTopicFlag := flag.String("alarm-sns-topic", "", "SNS topic ARN notified by generated CloudWatch alarms")
+	dryRunFlag := flag.Bool("dry-run", false, "Print alarm definitions or deletions without changing anything")
+	listenFlag := flag.String("listen", ":8080", "Address the serve command listens on")
+	ownershipFlag := flag.String("ownership-file", "", "JSON map routing quotas by service, quota code, account or quota tag to team destinations")
+	notifyTestFlag := flag.Bool("notify-test", false, "Print rendered notification payloads instead of sending them")
+	slackAPIURLFlag := flag.String("slack-api-url", slackDefaultAPIURL, "Slack Web API base URL")
 	logFileFlag := flag.String("log-file", "awsservicesquotafetcher.log", "Log file path")
+	cacheDirFlag := flag.String("cache-dir", defaultCacheDir(), "Directory for cached quota metadata")
+	cacheTTLFlag := flag.Duration("cache-ttl", 24*time.Hour, "How long cached quota metadata stays valid")
+	noCacheFlag := flag.Bool("no-cache", false, "Do not read or write the quota metadata cache")
+	refreshCacheFlag := flag.Bool("refresh-cache", false, "Ignore cached quota metadata and store fresh results")
+	regexFlag := flag.Bool("regex", false, "Treat the search query as a regular expression")
+	quotaCodeFlag := flag.String("quota-code", "", "Only include these comma-separated quota codes")
+	nameRegexFlag := flag.String("name-regex", "", "Only include quotas whose name matches this regex")
+	excludeFlag := flag.String("exclude", "", "Exclude quotas whose name or code matches this regex")
+	minUtilizationFlag := flag.Float64("min-utilization", 0, "Only include quotas at or above this utilization (%)")
+	onlyUsedFlag := flag.Bool("only-used", false, "Only include quotas with non-zero usage")
+	onlyAdjustableFlag := flag.Bool("only-adjustable", false, "Only include quotas that can be increased")
+	sortFlag := flag.String("sort", "", "Sort quotas by utilization, name or headroom")
+	topFlag := flag.Int("top", 0, "Keep only the first N quotas after sorting")
 
 	flag.Parse()
+	// The periodic reader and serve's ticker both panic on a non-positive interval
+	if *otelIntervalFlag <= 0 {
+		log.Fatal("❌ Error: --otel-interval must be greater than zero")
+	}
+	otelSettings := otelConfig{Endpoint: *otelEndpointFlag, Protocol: *otelProtocolFlag, Insecure: *otelInsecureFlag, Interval: *otelIntervalFlag, Prefix: *metricsPrefixFlag}
+	args := commandArgs()
 
 	// Initialize logging
 	logFile, err := os.OpenFile(*logFileFlag, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
@@ -655,8 +881,108 @@ func main() {
 		return
 	}
 
+	var cache *QuotaCache
+	if !*noCacheFlag {
+		cache = NewQuotaCache(*cacheDirFlag, *cacheTTLFlag, *refreshCacheFlag)
+	}
+
+	// catalog diff works on files only and needs no AWS credentials
+	if len(args) > 1 && args[0] == "catalog" && args[1] == "diff" {
+		if len(args) != 4 {
+			log.Fatal("❌ Error: catalog diff needs two files (e.g., catalog diff old.json new.json)")
+		}
+		runCatalogDiff(args[2], args[3])
+	}
+
+	if len(args) > 0 {
+		if *profileFlag == "" {
+			log.Fatal("❌ Error: --profile flag is required")
+		}
+		cfg := loadAWSConfig(*profileFlag, strings.Split(*regionsFlag, ",")[0])
+		switch args[0] {
+		case "catalog":
+			if len(args) != 2 || args[1] != "export" {
+				log.Fatal("❌ Error: usage is catalog export or catalog diff old.json new.json")
+			}
+			runCatalogExport(context.TODO(), cfg, strings.Split(*regionsFlag, ","), *outputFlag, cache)
+		case "search":
+			if len(args) < 2 {
+				log.Fatal("❌ Error: search needs a query (e.g., search nat gateway)")
+			}
+			runSearch(context.TODO(), cfg, strings.Join(args[1:], " "), *servicesFlag, *regexFlag, cache)
+		case "alarms":
+			if len(args) != 2 || (args[1] != "sync" && args[1] != "cleanup") {
+				log.Fatal("❌ Error: usage is alarms sync or alarms cleanup")
+			}
+			if args[1] == "sync" && *alarmSNSTopicFlag == "" {
+				if !*dryRunFlag {
+					log.Fatal("❌ Error: alarms sync needs --alarm-sns-topic so the alarms notify someone")
+				}
+				log.Println("⚠️ No --alarm-sns-topic set; the printed alarms have no actions")
+			}
+			regions := strings.Split(*regionsFlag, ",")
+			services := splitList(*servicesFlag)
+			if *servicesFlag == "all" {
+				var err error
+				if services, err = resolveAllServices(context.TODO(), cfg, regions, cache); err != nil {
+					log.Fatalf("❌ Error resolving services: %v", err)
+				}
+			}
+			runAlarms(context.TODO(), cfg, args[1], regions, services, alarmOptions{
+				Threshold:   *alarmThresholdFlag,
+				SNSTopicARN: *alarmSNSTopicFlag,
+				DryRun:      *dryRunFlag,
+			}, cache)
+		case "serve":
+			secret := os.Getenv("SLACK_SIGNING_SECRET")
+			if secret == "" && *otelEndpointFlag == "" {
+				log.Fatal("❌ Error: serve needs SLACK_SIGNING_SECRET for slash commands or --otel-endpoint for metrics")
+			}
+			thresholds := Thresholds{Warning: *warningThresholdFlag, Critical: *criticalThresholdFlag}
+			if *otelEndpointFlag != "" {
+				regions := strings.Split(*regionsFlag, ",")
+				services := splitList(*servicesFlag)
+				if *servicesFlag == "all" {
+					var err error
+					if services, err = resolveAllServices(context.TODO(), cfg, regions, cache); err != nil {
+						log.Fatalf("❌ Error resolving services: %v", err)
+					}
+				}
+				if len(services) == 0 {
+					log.Fatal("❌ Error: serve with --otel-endpoint needs --services")
+				}
+				filter, err := newQuotaFilter(*quotaCodeFlag, *nameRegexFlag, *excludeFlag, *minUtilizationFlag, *onlyUsedFlag, *onlyAdjustableFlag, *sortFlag, *topFlag)
+				if err != nil {
+					log.Fatalf("❌ Error: %v", err)
+				}
+				accountID := resolveAccountID(context.TODO(), cfg, cache)
+				sink, err := NewOTelSink(context.TODO(), otelSettings, accountID)
+				if err != nil {
+					log.Fatalf("❌ Error: %v", err)
+				}
+				go runOTelCollector(context.Background(), sink, *otelIntervalFlag, func(ctx context.Context) *Report {
+					quotas, fetchErrors := collectQuotas(ctx, cfg, services, regions, cache)
+					return &Report{
+						AccountID:   accountID,
+						Regions:     regions,
+						GeneratedAt: time.Now(),
+						Thresholds:  thresholds,
+						Quotas:      filter.Apply(quotas),
+						Errors:      fetchErrors,
+					}
+				})
+			}
+			runServe(cfg, *listenFlag, secret, thresholds, cache)
+		default:
+			log.Fatalf("❌ Error: unknown command %q", args[0])
+		}
+	}
+
 	if *listServicesFlag {
-		listValidServices()
+		if *profileFlag == "" {
+			log.Fatal("❌ Error: --profile flag is required")
+		}
+		listValidServices(context.TODO(), loadAWSConfig(*profileFlag, strings.Split(*regionsFlag, ",")[0]), cache)
 	}
 
 	if *listQuotasFlag != "" {
@@ -664,22 +990,97 @@ func main() {
 			log.Fatal("❌ Error: --profile flag is required")
 		}
 		service := *listQuotasFlag
-		listQuotasForService(service, strings.Split(*regionsFlag, ",")[0], *profileFlag)
+		listQuotasForService(context.TODO(), loadAWSConfig(*profileFlag, strings.Split(*regionsFlag, ",")[0]), service, cache)
 	}
 
 	// Show help if no service is provided
 	if *servicesFlag == "" {
 		fmt.Println("Usage: go run main.go --services ec2,vpc --regions us-east-1 --profile default --output quotas.csv")
-		fmt.Println("  --services         : Comma-separated list of AWS services to check quotas for (e.g., ec2,vpc)")
+		fmt.Println("  --services         : Comma-separated list of AWS services to check quotas for (e.g., ec2,vpc), or all")
 		fmt.Println("  --regions          : AWS region(s) (default: us-east-1)")
 		fmt.Println("  --profile          : AWS profile to use for authentication (required)")
-		fmt.Println("  --output           : Save the output as CSV (optional)")
-		fmt.Println("  --list-services    : List valid AWS services")
+		fmt.Println("  --output           : Save the output to a file (optional)")
+		fmt.Println("  --output-format    : Format of --output and S3 reports: csv, json (JSON Lines), parquet, html, markdown or xlsx (default: csv)")
+		fmt.Println("  --template         : Render the report through a Go template file to --output (or stdout) instead of")
+		fmt.Println("                       the table and --output-format; *.html templates use html/template escaping")
+		fmt.Println("  --github-step-summary : Append a Markdown report to $GITHUB_STEP_SUMMARY in GitHub Actions")
+		fmt.Println("  --list-services    : List AWS services with quotas and whether usage is collected")
 		fmt.Println("  --list-quotas      : List quotas for a service (e.g., --list-quotas ec2)")
-		fmt.Println("  --url-to-push      : Slack URL to push data")
-		fmt.Println("  --format           : Format to push data (table or json)")
-		fmt.Println("  --push-data-to-slack: Slack URL to push data")
-		fmt.Println("  --slack-token      : Slack API token for authentication (required when using --push-data-to-slack)")
+		fmt.Println("  --url-to-push      : Slack incoming webhook URL to push a Block Kit report to")
+		fmt.Println("  --slack-max-quotas : Maximum breaching quotas listed in a Slack report (default: 50, 0 for all)")
+		fmt.Println("  --teams-webhook-url: Microsoft Teams Workflows or incoming webhook URL for an Adaptive Card report")
+		fmt.Println("  --teams-max-quotas : Maximum breaching quotas listed in a Teams card (default: 25, 0 for all)")
+		fmt.Println("  --pagerduty-routing-key: PagerDuty Events API v2 routing key; opens one incident per breaching quota")
+		fmt.Println("  --opsgenie-api-key : Opsgenie API key; opens one alert per breaching quota")
+		fmt.Println("  --incident-severity: Lowest severity that opens a PagerDuty/Opsgenie alert (warning or critical; default: critical)")
+		fmt.Println("  --alert-state-file : File remembering alerted quotas so only new breaches, escalations and recoveries are notified")
+		fmt.Println("                       (default: none, every run notifies)")
+		fmt.Println("  --renotify-interval: Re-notify quotas still breaching after this long (default: 24h, 0 to never re-notify)")
+		fmt.Println("  --smtp-host        : SMTP server for an HTML email digest with the CSV attached (password from SMTP_PASSWORD)")
+		fmt.Println("  --smtp-port        : SMTP server port (default: 587)")
+		fmt.Println("  --smtp-username    : SMTP username")
+		fmt.Println("  --smtp-starttls    : Require STARTTLS before sending (default: true)")
+		fmt.Println("  --email-from       : Sender address for the email digest")
+		fmt.Println("  --email-to         : Comma-separated recipients of every email digest")
+		fmt.Println("  --email-to-warning : Comma-separated recipients when any quota is at warning or above")
+		fmt.Println("  --email-to-critical: Comma-separated recipients when any quota is critical")
+		fmt.Println("  --email-baseline   : Previous CSV report to show changes against (e.g., last week's)")
+		fmt.Println("  --webhook-template : Go text/template file defining body, method, url and headers of a generic webhook")
+		fmt.Println("  --webhook-url      : Webhook URL when the template defines no \"url\" (HMAC secret from WEBHOOK_HMAC_SECRET)")
+		fmt.Println("  --webhook-retries  : Retries for webhook requests that fail with 5xx or network errors (default: 3)")
+		fmt.Println("  --jira-url         : Jira base URL; opens a ticket per sustained breach (user from --jira-user, token from JIRA_API_TOKEN)")
+		fmt.Println("  --jira-project     : Jira project key (--jira-issue-type default: Task, --jira-done-transition default: Done)")
+		fmt.Println("  --github-repo      : GitHub owner/repo; opens an issue per sustained breach (token from GITHUB_TOKEN)")
+		fmt.Println("  --ticket-after-runs: Consecutive breaching runs before a ticket or issue is opened (default: 3)")
+		fmt.Println("  --cloudwatch-metrics: Publish Allocated, Used and UtilizedPerc per quota as CloudWatch custom metrics")
+		fmt.Println("  --cloudwatch-namespace: CloudWatch namespace for quota metrics (default: ServiceQuotaFetcher)")
+		fmt.Println("  --cloudwatch-region: Region to publish metrics to (default: first --regions entry)")
+		fmt.Println("  --cloudwatch-endpoint: CloudWatch endpoint override (e.g., a local stand-in)")
+		fmt.Println("  --otel-endpoint    : OTLP endpoint (host:port or URL) for quota gauges; OTEL_EXPORTER_OTLP_* variables also apply")
+		fmt.Println("  --otel-protocol    : OTLP protocol: grpc or http (default: grpc)")
+		fmt.Println("  --otel-insecure    : Use plaintext instead of TLS for OTLP")
+		fmt.Println("  --otel-interval    : How often serve refetches quotas and exports them (default: 5m)")
+		fmt.Println("  --metrics-prefix   : Metric name prefix for StatsD, Datadog, InfluxDB and OTLP (default: aws.service_quota)")
+		fmt.Println("  --statsd-addr      : StatsD host:port for limit, usage and utilization gauges over UDP")
+		fmt.Println("  --dogstatsd        : Send DogStatsD tags instead of encoding them in StatsD metric names")
+		fmt.Println("  --datadog-metrics  : Submit metrics to the Datadog API (key from DD_API_KEY, site via --datadog-url)")
+		fmt.Println("  --influxdb-url     : InfluxDB v2 URL for line protocol writes (with --influxdb-org, --influxdb-bucket; token from INFLUX_TOKEN)")
+		fmt.Println("  --s3-bucket        : Upload each run's report to s3://bucket/prefix/account=/region=/date=/run-HHMMSS.<format>")
+		fmt.Println("  --s3-prefix        : Key prefix for S3 reports (default: quota-reports)")
+		fmt.Println("  --s3-kms-key-id    : KMS key for SSE-KMS encryption of S3 reports")
+		fmt.Println("  --s3-endpoint      : S3 endpoint override (e.g., a local stand-in)")
+		fmt.Println("  --ownership-file   : JSON map routing quotas by service, quota code, account or quota tag to team Slack, Teams, email or PagerDuty")
+		fmt.Println("  --notify-test      : Print rendered notification payloads instead of sending them (sinks are skipped)")
+		fmt.Println("  --warning-threshold: Utilization (%) at which a quota is a warning (default: 80)")
+		fmt.Println("  --critical-threshold: Utilization (%) at which a quota is critical (default: 90)")
+		fmt.Println("  --slack-token      : Slack bot token for the Web API (required with --slack-channel)")
+		fmt.Println("  --slack-channel    : Slack channel to post the report, per-service thread replies and report file to")
+		fmt.Println("  --slack-file-format: Format of the full report uploaded to Slack (csv, json or none; default: csv)")
+		fmt.Println("  --push-data-to-slack: Deprecated alias for --slack-channel; takes a Slack API URL with a channel parameter")
+		fmt.Println("  --cache-dir        : Directory for cached quota metadata (default: user cache dir)")
+		fmt.Println("  --cache-ttl        : How long cached quota metadata stays valid (default: 24h)")
+		fmt.Println("  --no-cache         : Do not read or write the quota metadata cache")
+		fmt.Println("  --refresh-cache    : Ignore cached quota metadata and store fresh results")
+		fmt.Println("  --quota-code       : Only include these comma-separated quota codes")
+		fmt.Println("  --name-regex       : Only include quotas whose name matches this regex")
+		fmt.Println("  --exclude          : Exclude quotas whose name or code matches this regex")
+		fmt.Println("  --min-utilization  : Only include quotas at or above this utilization (%)")
+		fmt.Println("  --only-used        : Only include quotas with non-zero usage")
+		fmt.Println("  --only-adjustable  : Only include quotas that can be increased")
+		fmt.Println("  --sort             : Sort quotas by utilization, name or headroom")
+		fmt.Println("  --top              : Keep only the first N quotas after sorting")
+		fmt.Println("")
+		fmt.Println("Commands:")
+		fmt.Println("  search <query>     : Find quotas by name or code across all services (--regex for a regular expression)")
+		fmt.Println("  catalog export     : Write a JSON catalog of every quota default per region to --output (or stdout)")
+		fmt.Println("  catalog diff <old> <new>: Show quotas added, removed or whose defaults changed between two exports")
+		fmt.Println("  alarms sync        : Create or update a CloudWatch alarm for each quota of --services with a usage metric,")
+		fmt.Println("                       at --alarm-threshold (default: 80) using SERVICE_QUOTA(), notifying --alarm-sns-topic (required)")
+		fmt.Println("  alarms cleanup     : Delete the alarms created by alarms sync (limited to --services when given)")
+		fmt.Println("                       (--dry-run prints the alarm definitions or deletions without changing anything)")
+		fmt.Println("  serve              : Answer Slack slash commands (/quota ec2 us-west-2 L-1216C47A) on --listen (default: :8080)")
+		fmt.Println("                       at /slack/commands, verified with SLACK_SIGNING_SECRET; with --otel-endpoint,")
+		fmt.Println("                       also export --services over OTLP every --otel-interval")
 		log.Println("ℹ️ Displayed usage information")
 		os.Exit(0)
 	}
@@ -688,59 +1089,353 @@ func main() {
 		log.Fatal("❌ Error: --profile flag is required")
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithSharedConfigProfile(*profileFlag),
-		config.WithRegion(strings.Split(*regionsFlag, ",")[0]),
-	)
+	if *warningThresholdFlag > *criticalThresholdFlag {
+		log.Fatal("❌ Error: --warning-threshold must not exceed --critical-threshold")
+	}
+
+	// --push-data-to-slack predates bot mode; keep it working as an alias for --slack-channel
+	if *pushDataToSlackFlag != "" {
+		log.Println("⚠️ --push-data-to-slack is deprecated; use --slack-channel with --slack-token")
+		apiURL, channel, err := slackLegacyPushTarget(*pushDataToSlackFlag)
+		if err != nil {
+			log.Fatalf("❌ Error: invalid --push-data-to-slack: %v", err)
+		}
+		if *slackChannelFlag == "" {
+			if channel == "" {
+				log.Fatal("❌ Error: --push-data-to-slack URL has no channel parameter; use --slack-channel")
+			}
+			*slackChannelFlag = channel
+		}
+		if *slackAPIURLFlag == slackDefaultAPIURL {
+			*slackAPIURLFlag = apiURL
+		}
+	}
+
+	switch *slackFileFormatFlag {
+	case "csv", "json", "none":
+	default:
+		log.Fatalf("❌ Error: invalid --slack-file-format %q (use csv, json or none)", *slackFileFormatFlag)
+	}
+
+	if _, ok := reportContentTypes[*outputFormatFlag]; !ok {
+		log.Fatalf("❌ Error: invalid --output-format %q (use %s)", *outputFormatFlag, reportFormatNames())
+	}
+
+	var incidentSeverity Severity
+	switch *incidentSeverityFlag {
+	case "warning":
+		incidentSeverity = SeverityWarning
+	case "critical":
+		incidentSeverity = SeverityCritical
+	default:
+		log.Fatalf("❌ Error: invalid --incident-severity %q (use warning or critical)", *incidentSeverityFlag)
+	}
+
+	var ownership *Ownership
+	if *ownershipFlag != "" {
+		if ownership, err = LoadOwnership(*ownershipFlag); err != nil {
+			log.Fatalf("❌ Error loading ownership map: %v", err)
+		}
+	}
+
+	filter, err := newQuotaFilter(*quotaCodeFlag, *nameRegexFlag, *excludeFlag, *minUtilizationFlag, *onlyUsedFlag, *onlyAdjustableFlag, *sortFlag, *topFlag)
 	if err != nil {
-		log.Fatalf("❌ Error loading AWS config: %v", err)
+		log.Fatalf("❌ Error: %v", err)
 	}
 
-	var allQuotas []QuotaInfo
+	cfg := loadAWSConfig(*profileFlag, strings.Split(*regionsFlag, ",")[0])
+
 	services := strings.Split(*servicesFlag, ",")
 	regions := strings.Split(*regionsFlag, ",")
 
-	for _, service := range services {
-		for _, region := range regions {
-			cfg.Region = region
-			log.Printf("🔍 Fetching quotas for %s in region: %s", service, region)
+	if *servicesFlag == "all" {
+		services, err = resolveAllServices(context.TODO(), cfg, regions, cache)
+		if err != nil {
+			log.Fatalf("❌ Error resolving services: %v", err)
+		}
+		log.Printf("🔍 Sweeping %d services with quotas", len(services))
+	}
 
-			quotas, err := FetchServiceQuotas(context.TODO(), cfg, service, region)
-			if err != nil {
-				log.Printf("❌ Error fetching quotas for %s: %v", service, err)
-				continue
-			}
-			allQuotas = append(allQuotas, quotas...)
+	// Filter once so every output and notification sees the same quotas
+	quotas, fetchErrors := collectQuotas(context.TODO(), cfg, services, regions, cache)
+	allQuotas := filter.Apply(quotas)
+
+	report := &Report{
+		AccountID:   resolveAccountID(context.TODO(), cfg, cache),
+		Regions:     regions,
+		GeneratedAt: time.Now(),
+		Thresholds:  Thresholds{Warning: *warningThresholdFlag, Critical: *criticalThresholdFlag},
+		Quotas:      allQuotas,
+		Errors:      fetchErrors,
+	}
+
+	// A template replaces both the console table and --output-format
+	if *templateFlag != "" {
+		if err := RenderTemplate(report, *templateFlag, *outputFlag); err != nil {
+			log.Fatalf("❌ Error rendering template: %v", err)
+		}
+		if *outputFlag != "" {
+			log.Printf("✅ Rendered %s to %s", *templateFlag, *outputFlag)
+		}
+	} else {
+		fmt.Println("Service Name\tQuota Name\tRegion\tAllocated Quota\tUsed Quota\tUtilized (%)")
+		for _, q := range allQuotas {
+			fmt.Printf("%s\t%s\t%s\t%.2f\t%.2f\t%.2f%%\n", q.ServiceName, q.QuotaName, q.Region, q.Allocated, q.Used, q.UtilizedPerc)
 		}
 	}
 
-	fmt.Println("Service Name\tQuota Name\tRegion\tAllocated Quota\tUsed Quota\tUtilized (%)")
-	for _, q := range allQuotas {
-		fmt.Printf("%s\t%s\t%s\t%.2f\t%.2f\t%.2f%%\n", q.ServiceName, q.QuotaName, q.Region, q.Allocated, q.Used, q.UtilizedPerc)
+	if *outputFlag != "" && *templateFlag == "" {
+		if err := SaveReport(report, *outputFlag, *outputFormatFlag); err != nil {
+			log.Fatalf("❌ Error saving report: %v", err)
+		}
+		log.Printf("✅ Saved quotas to %s file: %s", *outputFormatFlag, *outputFlag)
+	}
+	if *stepSummaryFlag {
+		if err := AppendStepSummary(report); err != nil {
+			log.Fatalf("❌ Error writing job summary: %v", err)
+		}
+		log.Println("✅ Appended quota summary to $GITHUB_STEP_SUMMARY")
 	}
 
-	if *outputFlag != "" {
-		if err := SaveToCSV(allQuotas, *outputFlag); err != nil {
-			log.Fatalf("❌ Error saving CSV: %v", err)
+	var sinks []Sink
+	if *cloudWatchMetricsFlag {
+		metricsCfg := cfg.Copy()
+		metricsCfg.Region = regions[0]
+		if *cloudWatchRegionFlag != "" {
+			metricsCfg.Region = *cloudWatchRegionFlag
 		}
-		log.Printf("✅ Saved quotas to CSV file: %s", *outputFlag)
+		sinks = append(sinks, NewCloudWatchMetrics(metricsCfg, *cloudWatchNamespaceFlag, *cloudWatchEndpointFlag))
+	}
+	if *otelEndpointFlag != "" {
+		sink, err := NewOTelSink(context.TODO(), otelSettings, report.AccountID)
+		if err != nil {
+			log.Fatalf("❌ Error: %v", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	if *statsdAddrFlag != "" {
+		sinks = append(sinks, &StatsDSink{Addr: *statsdAddrFlag, Prefix: *metricsPrefixFlag, DogStatsD: *dogstatsdFlag})
+	}
+	if *datadogFlag {
+		apiKey := os.Getenv("DD_API_KEY")
+		if apiKey == "" {
+			log.Fatal("❌ Error: DD_API_KEY must be set when using --datadog-metrics")
+		}
+		sinks = append(sinks, &DatadogSink{APIKey: apiKey, URL: *datadogURLFlag, Prefix: *metricsPrefixFlag, Client: newNotifyHTTPClient()})
+	}
+	if *influxURLFlag != "" {
+		if *influxOrgFlag == "" || *influxBucketFlag == "" {
+			log.Fatal("❌ Error: --influxdb-org and --influxdb-bucket flags are required when using --influxdb-url")
+		}
+		sinks = append(sinks, &InfluxDBSink{
+			URL:         *influxURLFlag,
+			Org:         *influxOrgFlag,
+			Bucket:      *influxBucketFlag,
+			Token:       os.Getenv("INFLUX_TOKEN"),
+			Measurement: strings.ReplaceAll(*metricsPrefixFlag, ".", "_"),
+			Client:      newNotifyHTTPClient(),
+		})
+	}
+	if *s3BucketFlag != "" {
+		s3Cfg := cfg.Copy()
+		s3Cfg.Region = regions[0]
+		sinks = append(sinks, NewS3Sink(s3Cfg, *s3BucketFlag, *s3PrefixFlag, *outputFormatFlag, *s3KMSKeyFlag, *s3EndpointFlag))
+	}
+	// Sink failures are reported after notifications so alerts still go out
+	slackFileFormat := *slackFileFormatFlag
+	if slackFileFormat == "none" {
+		slackFileFormat = ""
 	}
 
+	var notifiers []Notifier
 	if *slackURLFlag != "" {
-		if err := pushToSlack(*slackURLFlag, allQuotas, *formatFlag); err != nil {
-			log.Fatalf("❌ Error pushing data to Slack: %v", err)
+		notifiers = append(notifiers, &SlackWebhookNotifier{URL: *slackURLFlag, MaxQuotas: *slackMaxQuotasFlag, Client: newNotifyHTTPClient()})
+	}
+	if *slackChannelFlag != "" {
+		if *slackTokenFlag == "" {
+			log.Fatal("❌ Error: --slack-token flag is required when using --slack-channel")
+		}
+		notifiers = append(notifiers, &SlackBotNotifier{
+			Token:      *slackTokenFlag,
+			Channel:    *slackChannelFlag,
+			APIURL:     *slackAPIURLFlag,
+			MaxQuotas:  *slackMaxQuotasFlag,
+			FileFormat: slackFileFormat,
+			Client:     newNotifyHTTPClient(),
+		})
+	}
+	if *teamsURLFlag != "" {
+		notifiers = append(notifiers, &TeamsNotifier{URL: *teamsURLFlag, MaxQuotas: *teamsMaxQuotasFlag, Client: newNotifyHTTPClient()})
+	}
+	var email *EmailNotifier
+	if *smtpHostFlag != "" {
+		if *emailFromFlag == "" {
+			log.Fatal("❌ Error: --email-from flag is required when using --smtp-host")
 		}
-		log.Println("✅ Pushed data to Slack")
+		email = &EmailNotifier{
+			Host:     *smtpHostFlag,
+			Port:     *smtpPortFlag,
+			Username: *smtpUserFlag,
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     *emailFromFlag,
+			StartTLS: *smtpStartTLSFlag,
+			Recipients: map[Severity][]string{
+				SeverityOK:       splitList(*emailToFlag),
+				SeverityWarning:  splitList(*emailToWarningFlag),
+				SeverityCritical: splitList(*emailToCriticalFlag),
+			},
+		}
+		if *emailBaselineFlag != "" {
+			if email.Previous, err = LoadCSV(*emailBaselineFlag); err != nil {
+				log.Printf("⚠️ Ignoring email baseline: %v", err)
+			}
+		}
+		notifiers = append(notifiers, email)
 	}
 
-	if *pushDataToSlackFlag != "" {
-		if *slackTokenFlag == "" {
-			log.Fatal("❌ Error: --slack-token flag is required when using --push-data-to-slack")
+	if *webhookTemplateFlag != "" {
+		tmpl, err := LoadWebhookTemplate(*webhookTemplateFlag)
+		if err != nil {
+			log.Fatalf("❌ Error: %v", err)
 		}
-		if err := pushDataToSlack(*pushDataToSlackFlag, *slackTokenFlag, allQuotas); err != nil {
-			log.Fatalf("❌ Error pushing data to Slack: %v", err)
+		notifiers = append(notifiers, &WebhookNotifier{
+			Template:   tmpl,
+			URL:        *webhookURLFlag,
+			HMACSecret: os.Getenv("WEBHOOK_HMAC_SECRET"),
+			Retries:    *webhookRetriesFlag,
+			Backoff:    time.Second,
+			Client:     newNotifyHTTPClient(),
+		})
+	}
+
+	var incidents []Notifier
+	if *pagerDutyKeyFlag != "" {
+		incidents = append(incidents, &IncidentNotifier{
+			Label:       "PagerDuty",
+			API:         &PagerDutyEvents{RoutingKey: *pagerDutyKeyFlag, URL: *pagerDutyURLFlag, Client: newNotifyHTTPClient()},
+			MinSeverity: incidentSeverity,
+		})
+	}
+	if *opsgenieKeyFlag != "" {
+		incidents = append(incidents, &IncidentNotifier{
+			Label:       "Opsgenie",
+			API:         &OpsgenieAlerts{APIKey: *opsgenieKeyFlag, URL: *opsgenieURLFlag, Client: newNotifyHTTPClient()},
+			MinSeverity: incidentSeverity,
+		})
+	}
+	notifiers = append(notifiers, incidents...)
+
+	// Alert state is shared by all notifiers so each change is announced once everywhere
+	var alerts *AlertState
+	if *alertStateFlag != "" && (len(notifiers) > 0 || ownership != nil || *jiraURLFlag != "" || *githubRepoFlag != "") {
+		if alerts, err = LoadAlertState(*alertStateFlag); err != nil {
+			log.Fatalf("❌ Error loading alert state: %v", err)
+		}
+		sustainedRuns := 0
+		if *jiraURLFlag != "" || *githubRepoFlag != "" {
+			sustainedRuns = *ticketRunsFlag
+		}
+		report.Events = alerts.Evaluate(report, quotas, *renotifyFlag, sustainedRuns)
+		if report.Events == nil {
+			report.Events = []AlertEvent{}
+		}
+	}
+
+	routes := []notifyRoute{{Report: report, Notifiers: notifiers}}
+	if ownership != nil {
+		// Owned quotas go to their team; the default destinations only get what nobody owns,
+		// except global incident notifiers, which also page for teams without a routing key
+		ownership.LoadTags(context.TODO(), report, serviceQuotasTags(cfg))
+		owned, unowned := ownership.Split(report)
+		routes[0].Report = unowned
+		defaults := ownerDefaults{
+			SlackToken:       *slackTokenFlag,
+			SlackAPIURL:      *slackAPIURLFlag,
+			SlackMaxQuotas:   *slackMaxQuotasFlag,
+			SlackFileFormat:  slackFileFormat,
+			TeamsMaxQuotas:   *teamsMaxQuotasFlag,
+			Email:            email,
+			PagerDutyURL:     *pagerDutyURLFlag,
+			IncidentSeverity: incidentSeverity,
+			Incidents:        incidents,
+		}
+		for i := range ownership.Owners {
+			owner := &ownership.Owners[i]
+			sub, ok := owned[owner]
+			if !ok {
+				continue
+			}
+			ownerNotifiers, err := owner.Notifiers(defaults, newNotifyHTTPClient)
+			if err != nil {
+				log.Fatalf("❌ Error: %v", err)
+			}
+			routes = append(routes, notifyRoute{Team: owner.Name, Report: sub, Notifiers: ownerNotifiers})
 		}
-		log.Println("✅ Pushed data to Slack with token")
+	}
+
+	// Issue trackers are shared across teams and see every quota
+	var trackers []Notifier
+	if *jiraURLFlag != "" {
+		if *jiraProjectFlag == "" {
+			log.Fatal("❌ Error: --jira-project flag is required when using --jira-url")
+		}
+		trackers = append(trackers, &TicketNotifier{
+			Label: "Jira",
+			API: &JiraIssues{
+				URL:            *jiraURLFlag,
+				User:           *jiraUserFlag,
+				Token:          os.Getenv("JIRA_API_TOKEN"),
+				Project:        *jiraProjectFlag,
+				IssueType:      *jiraIssueTypeFlag,
+				DoneTransition: *jiraDoneFlag,
+				Client:         newNotifyHTTPClient(),
+			},
+			MinRuns: *ticketRunsFlag,
+			State:   alerts,
+		})
+	}
+	if *githubRepoFlag != "" {
+		trackers = append(trackers, &TicketNotifier{
+			Label:   "GitHub",
+			API:     &GitHubIssues{Repo: *githubRepoFlag, Token: os.Getenv("GITHUB_TOKEN"), APIURL: *githubAPIURLFlag, Client: newNotifyHTTPClient()},
+			MinRuns: *ticketRunsFlag,
+			State:   alerts,
+		})
+	}
+	if len(trackers) > 0 {
+		if alerts == nil {
+			log.Fatal("❌ Error: Jira and GitHub tickets need --alert-state-file to count consecutive breaches")
+		}
+		// Trackers run every time so a ticket that failed to open is retried on the next run
+		routes = append(routes, notifyRoute{Report: report, Notifiers: trackers, EveryRun: true})
+	}
+
+	if *notifyTestFlag {
+		previewAll(os.Stdout, routes)
+		closeSinks(context.TODO(), sinks)
+		log.Println("ℹ️ Rendered notification payloads without sending or publishing metrics")
+		return
+	}
+
+	sinksFailed := publishAll(context.TODO(), sinks, report)
+	closeSinks(context.TODO(), sinks)
+
+	failed := notifyAll(context.TODO(), routes)
+	// Save even when some notifications failed: trackers may have opened tickets that
+	// must not be opened again, and ongoing breaches are re-announced by --renotify-interval
+	if alerts != nil {
+		if len(report.Events) == 0 {
+			log.Println("ℹ️ No alert changes since the previous run, skipped notifications")
+		}
+		if err := alerts.Save(); err != nil {
+			log.Printf("❌ Error saving alert state: %v", err)
+		}
+	}
+	if failed > 0 {
+		log.Fatalf("❌ Error: %d notifications failed", failed)
+	}
+	if sinksFailed > 0 {
+		log.Fatalf("❌ Error: %d of %d sinks failed", sinksFailed, len(sinks))
 	}
 
 	log.Println("🏁 Finished awsservicesquotafetcher")
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// markdownCell keeps table cells from breaking the Markdown table
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// GitHub rejects PR comments over 65,536 characters and step summaries over 1 MiB. Limits are
+// counted in bytes, which is never fewer than the characters GitHub counts
+const (
+	markdownCommentLimit = 65536
+	stepSummaryLimit     = 1 << 20
+	// markdownNoteReserve leaves room for the omission notes and the closing tags
+	markdownNoteReserve = 256
+)
+
+// writeMarkdown writes a summary table plus a collapsible section per service, sized for
+// PR comments; services with breaches are expanded
+func writeMarkdown(w io.Writer, report *Report) error {
+	return writeMarkdownWithin(w, report, markdownCommentLimit)
+}
+
+// writeMarkdownWithin writes the Markdown report in at most limit bytes. The summary table is
+// always kept; per-service sections are cut short with a note once the budget runs out
+func writeMarkdownWithin(w io.Writer, report *Report, limit int) error {
+	services, byService := report.ByService()
+
+	var b strings.Builder
+	counts := report.SeverityCounts()
+	fmt.Fprintf(&b, "## AWS Service Quotas — account %s\n\n", report.AccountID)
+	fmt.Fprintf(&b, "Regions %s · generated %s · warning ≥ %.0f%%, critical ≥ %.0f%%\n\n",
+		strings.Join(report.Regions, ", "), report.GeneratedAt.UTC().Format(time.RFC3339), report.Thresholds.Warning, report.Thresholds.Critical)
+	fmt.Fprintf(&b, "🔴 **Critical:** %d · 🟠 **Warning:** %d · 🟢 **OK:** %d\n\n", counts[SeverityCritical], counts[SeverityWarning], counts[SeverityOK])
+
+	b.WriteString("| Service | Quotas | Critical | Warning | Highest utilization |\n")
+	b.WriteString("|---|---:|---:|---:|---:|\n")
+	for _, service := range services {
+		quotas := byService[service]
+		var critical, warning int
+		for _, q := range quotas {
+			switch report.Severity(q) {
+			case SeverityCritical:
+				critical++
+			case SeverityWarning:
+				warning++
+			}
+		}
+		fmt.Fprintf(&b, "| %s %s | %d | %d | %d | %.2f%% |\n", severityEmoji(report.Severity(quotas[0])), markdownCell(service), len(quotas), critical, warning, quotas[0].UtilizedPerc)
+	}
+
+	const sectionEnd = "\n</details>\n"
+	budget := limit - markdownNoteReserve
+	for i, service := range services {
+		quotas := byService[service]
+		open := ""
+		if report.Severity(quotas[0]) > SeverityOK {
+			open = " open"
+		}
+		head := fmt.Sprintf("\n<details%s>\n<summary><b>%s</b> — %d quotas, highest %.2f%%</summary>\n\n", open, markdownCell(service), len(quotas), quotas[0].UtilizedPerc) +
+			"| | Region | Quota | Code | Allocated | Used | Utilization |\n" +
+			"|---|---|---|---|---:|---:|---:|\n"
+		if b.Len()+len(head)+len(sectionEnd) > budget {
+			fmt.Fprintf(&b, "\n_%d more services omitted to stay within GitHub's size limit._\n", len(services)-i)
+			break
+		}
+		b.WriteString(head)
+		for j, q := range quotas {
+			row := fmt.Sprintf("| %s | %s | %s | `%s` | %s | %s | %.2f%% |\n",
+				severityEmoji(report.Severity(q)), q.Region, markdownCell(q.QuotaName), q.QuotaCode,
+				strconv.FormatFloat(q.Allocated, 'f', -1, 64), strconv.FormatFloat(q.Used, 'f', -1, 64), q.UtilizedPerc)
+			if b.Len()+len(row)+len(sectionEnd) > budget {
+				fmt.Fprintf(&b, "\n_%d more quotas omitted._\n", len(quotas)-j)
+				break
+			}
+			b.WriteString(row)
+		}
+		b.WriteString(sectionEnd)
+	}
+
+	// A trailing blank line keeps appended summaries from running into each other
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// AppendStepSummary appends the Markdown report to the file GitHub Actions exposes as $GITHUB_STEP_SUMMARY
+func AppendStepSummary(report *Report) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return fmt.Errorf("GITHUB_STEP_SUMMARY is not set; --github-step-summary only works inside GitHub Actions")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	// Earlier steps' output in the same file counts towards the limit
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	room := stepSummaryLimit - int(info.Size())
+	if room < 2*markdownNoteReserve {
+		file.Close()
+		return fmt.Errorf("GITHUB_STEP_SUMMARY already holds %d bytes; no room left for the quota summary", info.Size())
+	}
+	if err := writeMarkdownWithin(file, report, room); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdownEscapesCells(t *testing.T) {
+	report := newTestReport(1)
+	report.Quotas[0].ServiceName = "Odd|Service"
+	report.Quotas[0].QuotaName = "Pipes | and <script>alert(1)</script>\nsecond line"
+
+	var out strings.Builder
+	if err := writeMarkdown(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	md := out.String()
+	for _, want := range []string{`Odd\|Service`, `Pipes \| and &lt;script&gt;alert(1)&lt;/script&gt; second line`} {
+		if !strings.Contains(md, want) {
+			t.Errorf("output is missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "<script>") {
+		t.Errorf("raw HTML leaked into the output:\n%s", md)
+	}
+	for _, line := range strings.Split(md, "\n") {
+		if strings.Contains(line, "Pipes") && !strings.HasSuffix(line, "|") {
+			t.Errorf("quota row was broken across lines: %q", line)
+		}
+	}
+}
+
+func TestWriteMarkdownFitsCommentLimit(t *testing.T) {
+	report := newTestReport(3000)
+
+	var out strings.Builder
+	if err := writeMarkdown(&out, report); err != nil {
+		t.Fatal(err)
+	}
+	md := out.String()
+	if len(md) > markdownCommentLimit {
+		t.Fatalf("output is %d bytes, want at most %d", len(md), markdownCommentLimit)
+	}
+	if !strings.Contains(md, "more quotas omitted") {
+		t.Error("truncated output has no omission note")
+	}
+	if opened, closed := strings.Count(md, "<details"), strings.Count(md, "</details>"); opened != closed {
+		t.Errorf("%d <details> opened but %d closed", opened, closed)
+	}
+	// The summary table still covers every service
+	for _, service := range []string{"Service 0", "Service 1", "Service 2"} {
+		if !strings.Contains(md, "| 🔴 "+service+" | 1000 |") && !strings.Contains(md, "| 🟠 "+service+" | 1000 |") {
+			t.Errorf("summary row for %s is missing", service)
+		}
+	}
+}
+
+func TestWriteMarkdownOmitsServices(t *testing.T) {
+	var out strings.Builder
+	if err := writeMarkdownWithin(&out, newTestReport(300), 4096); err != nil {
+		t.Fatal(err)
+	}
+	md := out.String()
+	if len(md) > 4096 {
+		t.Fatalf("output is %d bytes, want at most 4096", len(md))
+	}
+	if !strings.Contains(md, "more quotas omitted") || !strings.Contains(md, "more services omitted") {
+		t.Errorf("output is missing omission notes:\n%s", md)
+	}
+}
+
+func TestWriteMarkdownSmallReportUntouched(t *testing.T) {
+	var out strings.Builder
+	if err := writeMarkdown(&out, newTestReport(6)); err != nil {
+		t.Fatal(err)
+	}
+	if md := out.String(); strings.Contains(md, "omitted") || strings.Count(md, "| us-east-1 |") != 6 {
+		t.Errorf("small report was truncated:\n%s", md)
+	}
+}
+
+func TestAppendStepSummaryCountsExistingContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	earlier := strings.Repeat("x", stepSummaryLimit-8192)
+	if err := os.WriteFile(path, []byte(earlier), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	if err := AppendStepSummary(newTestReport(3000)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > stepSummaryLimit {
+		t.Errorf("step summary is %d bytes, want at most %d", info.Size(), stepSummaryLimit)
+	}
+
+	full := filepath.Join(t.TempDir(), "full.md")
+	if err := os.WriteFile(full, []byte(strings.Repeat("x", stepSummaryLimit)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_STEP_SUMMARY", full)
+	if err := AppendStepSummary(newTestReport(1)); err == nil {
+		t.Error("appending to a full step summary succeeded")
+	}
+}
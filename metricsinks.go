@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultMetricsPrefix = "aws.service_quota"
+	datadogDefaultURL    = "https://api.datadoghq.com"
+	// statsdMaxPacket keeps UDP datagrams under a typical 1500-byte MTU
+	statsdMaxPacket = 1432
+	// datadogMaxSeries and influxMaxLines bound the size of each HTTP request
+	datadogMaxSeries = 500
+	influxMaxLines   = 5000
+)
+
+// metricPoint is one gauge value; every sink emits the same names and tags
+type metricPoint struct {
+	Name  string // limit, usage or utilization
+	Value float64
+	Tags  [][2]string
+}
+
+// quotaTags are the tags shared by all metric sinks
+func quotaTags(report *Report, q QuotaInfo) [][2]string {
+	return [][2]string{
+		{"account", report.AccountID},
+		{"service", q.ServiceName},
+		{"quota_code", q.QuotaCode},
+		{"region", q.Region},
+	}
+}
+
+func quotaMetricPoints(report *Report) []metricPoint {
+	points := make([]metricPoint, 0, len(report.Quotas)*3)
+	for _, q := range report.Quotas {
+		tags := quotaTags(report, q)
+		points = append(points,
+			metricPoint{Name: "limit", Value: q.Allocated, Tags: tags},
+			metricPoint{Name: "usage", Value: q.Used, Tags: tags},
+			metricPoint{Name: "utilization", Value: q.UtilizedPerc, Tags: tags},
+		)
+	}
+	return points
+}
+
+// StatsDSink sends gauges over UDP. DogStatsD carries the tags natively; plain
+// StatsD has no tags, so their values are appended to the metric name instead.
+type StatsDSink struct {
+	Addr      string
+	Prefix    string
+	DogStatsD bool
+}
+
+func (s *StatsDSink) Name() string {
+	if s.DogStatsD {
+		return "DogStatsD " + s.Addr
+	}
+	return "StatsD " + s.Addr
+}
+
+// statsdName keeps metric name parts free of StatsD separators
+func statsdName(s string) string {
+	return strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "/", "_").Replace(s)
+}
+
+func (s *StatsDSink) line(p metricPoint) string {
+	if s.DogStatsD {
+		tags := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			tags = append(tags, statsdName(t[0])+":"+statsdName(t[1]))
+		}
+		return fmt.Sprintf("%s.%s:%s|g|#%s", s.Prefix, p.Name, strconv.FormatFloat(p.Value, 'f', -1, 64), strings.Join(tags, ","))
+	}
+	parts := []string{s.Prefix, p.Name}
+	for _, t := range p.Tags {
+		parts = append(parts, statsdName(strings.ReplaceAll(t[1], ".", "_")))
+	}
+	return fmt.Sprintf("%s:%s|g", strings.Join(parts, "."), strconv.FormatFloat(p.Value, 'f', -1, 64))
+}
+
+func (s *StatsDSink) Publish(ctx context.Context, report *Report) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("error connecting to %s: %v", s.Addr, err)
+	}
+	defer conn.Close()
+
+	// Pack as many lines per datagram as fit
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+	for _, p := range quotaMetricPoints(report) {
+		line := s.line(p)
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsdMaxPacket {
+			if err := flush(); err != nil {
+				return fmt.Errorf("error sending metrics: %v", err)
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteString("\n")
+		}
+		packet.WriteString(line)
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("error sending metrics: %v", err)
+	}
+	return nil
+}
+
+// DatadogSink submits gauges through the Datadog metrics API v2
+type DatadogSink struct {
+	APIKey string
+	URL    string // site API base, e.g. https://api.datadoghq.eu
+	Prefix string
+	Client *http.Client
+}
+
+func (d *DatadogSink) Name() string {
+	return "Datadog " + d.URL
+}
+
+func (d *DatadogSink) Publish(ctx context.Context, report *Report) error {
+	type point struct {
+		Timestamp int64   `json:"timestamp"`
+		Value     float64 `json:"value"`
+	}
+	type series struct {
+		Metric string   `json:"metric"`
+		Type   int      `json:"type"` // 3 is gauge
+		Points []point  `json:"points"`
+		Tags   []string `json:"tags"`
+	}
+
+	var all []series
+	for _, p := range quotaMetricPoints(report) {
+		tags := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			tags = append(tags, t[0]+":"+t[1])
+		}
+		all = append(all, series{
+			Metric: d.Prefix + "." + p.Name,
+			Type:   3,
+			Points: []point{{Timestamp: report.GeneratedAt.Unix(), Value: p.Value}},
+			Tags:   tags,
+		})
+	}
+
+	target := strings.TrimSuffix(d.URL, "/") + "/api/v2/series"
+	headers := map[string]string{"DD-API-KEY": d.APIKey}
+	for start := 0; start < len(all); start += datadogMaxSeries {
+		end := min(start+datadogMaxSeries, len(all))
+		if err := postJSON(ctx, d.Client, target, headers, map[string]interface{}{"series": all[start:end]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InfluxDBSink writes line protocol to the InfluxDB v2 write API, one point per quota
+type InfluxDBSink struct {
+	URL         string
+	Org         string
+	Bucket      string
+	Token       string
+	Measurement string
+	Client      *http.Client
+}
+
+func (i *InfluxDBSink) Name() string {
+	return "InfluxDB " + i.URL + " bucket " + i.Bucket
+}
+
+// influxEscape escapes tag keys and values as line protocol requires
+func influxEscape(s string) string {
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
+
+func (i *InfluxDBSink) line(report *Report, q QuotaInfo) string {
+	var b strings.Builder
+	b.WriteString(strings.NewReplacer(",", `\,`, " ", `\ `).Replace(i.Measurement))
+	for _, t := range quotaTags(report, q) {
+		if t[1] == "" {
+			continue // line protocol rejects empty tag values
+		}
+		fmt.Fprintf(&b, ",%s=%s", influxEscape(t[0]), influxEscape(t[1]))
+	}
+	fmt.Fprintf(&b, " limit=%s,usage=%s,utilization=%s %d",
+		strconv.FormatFloat(q.Allocated, 'f', -1, 64),
+		strconv.FormatFloat(q.Used, 'f', -1, 64),
+		strconv.FormatFloat(q.UtilizedPerc, 'f', -1, 64),
+		report.GeneratedAt.Unix())
+	return b.String()
+}
+
+func (i *InfluxDBSink) Publish(ctx context.Context, report *Report) error {
+	query := url.Values{"org": {i.Org}, "bucket": {i.Bucket}, "precision": {"s"}}
+	target := strings.TrimSuffix(i.URL, "/") + "/api/v2/write?" + query.Encode()
+
+	lines := make([]string, 0, len(report.Quotas))
+	for _, q := range report.Quotas {
+		lines = append(lines, i.line(report, q))
+	}
+	for start := 0; start < len(lines); start += influxMaxLines {
+		end := min(start+influxMaxLines, len(lines))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(strings.Join(lines[start:end], "\n")))
+		if err != nil {
+			return fmt.Errorf("error creating HTTP request: %v", err)
+		}
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+		if i.Token != "" {
+			req.Header.Set("Authorization", "Token "+i.Token)
+		}
+		resp, err := i.Client.Do(req)
+		if err != nil {
+			return fmt.Errorf("error sending HTTP request: %v", err)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("influxdb returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newMetricsTestReport() *Report {
+	return &Report{
+		AccountID:   "123456789012",
+		GeneratedAt: time.Unix(1700000000, 0),
+		Quotas: []QuotaInfo{
+			{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 1152, Used: 96, UtilizedPerc: 8.333},
+		},
+	}
+}
+
+// readStatsD publishes through a StatsD sink to a local UDP listener and returns the datagrams
+func readStatsD(t *testing.T, sink *StatsDSink, report *Report) []string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	sink.Addr = conn.LocalAddr().String()
+	if err := sink.Publish(context.Background(), report); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var packets []string
+	buf := make([]byte, 65536)
+	for {
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return packets
+		}
+		packets = append(packets, string(buf[:n]))
+	}
+}
+
+func TestStatsDSinkLineFormat(t *testing.T) {
+	tests := []struct {
+		dogstatsd bool
+		want      string
+	}{
+		{false, "aws.service_quota.limit.123456789012.ec2.L-1216C47A.us-east-1:1152|g\n" +
+			"aws.service_quota.usage.123456789012.ec2.L-1216C47A.us-east-1:96|g\n" +
+			"aws.service_quota.utilization.123456789012.ec2.L-1216C47A.us-east-1:8.333|g"},
+		{true, "aws.service_quota.limit:1152|g|#account:123456789012,service:ec2,quota_code:L-1216C47A,region:us-east-1\n" +
+			"aws.service_quota.usage:96|g|#account:123456789012,service:ec2,quota_code:L-1216C47A,region:us-east-1\n" +
+			"aws.service_quota.utilization:8.333|g|#account:123456789012,service:ec2,quota_code:L-1216C47A,region:us-east-1"},
+	}
+	for _, tt := range tests {
+		packets := readStatsD(t, &StatsDSink{Prefix: defaultMetricsPrefix, DogStatsD: tt.dogstatsd}, newMetricsTestReport())
+		if len(packets) != 1 || packets[0] != tt.want {
+			t.Errorf("dogstatsd=%v sent %q, want %q", tt.dogstatsd, packets, tt.want)
+		}
+	}
+}
+
+func TestStatsDSinkPacketSize(t *testing.T) {
+	report := newTestReport(100)
+	packets := readStatsD(t, &StatsDSink{Prefix: defaultMetricsPrefix, DogStatsD: true}, report)
+	lines := 0
+	for _, p := range packets {
+		if len(p) > statsdMaxPacket {
+			t.Errorf("datagram of %d bytes exceeds %d", len(p), statsdMaxPacket)
+		}
+		lines += len(strings.Split(p, "\n"))
+	}
+	if len(packets) < 2 || lines != 3*len(report.Quotas) {
+		t.Errorf("got %d lines in %d datagrams, want %d lines split across several", lines, len(packets), 3*len(report.Quotas))
+	}
+}
+
+func TestDatadogSinkSeries(t *testing.T) {
+	var body struct {
+		Series []struct {
+			Metric string `json:"metric"`
+			Type   int    `json:"type"`
+			Points []struct {
+				Timestamp int64   `json:"timestamp"`
+				Value     float64 `json:"value"`
+			} `json:"points"`
+			Tags []string `json:"tags"`
+		} `json:"series"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/series" || r.Header.Get("DD-API-KEY") != "dd-key" {
+			t.Errorf("request %s with key %q", r.URL.Path, r.Header.Get("DD-API-KEY"))
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := &DatadogSink{APIKey: "dd-key", URL: server.URL + "/", Prefix: "platform.quota", Client: server.Client()}
+	if err := sink.Publish(context.Background(), newMetricsTestReport()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(body.Series) != 3 {
+		t.Fatalf("got %d series, want 3", len(body.Series))
+	}
+	s := body.Series[2]
+	if s.Metric != "platform.quota.utilization" || s.Type != 3 || len(s.Points) != 1 || s.Points[0].Timestamp != 1700000000 || s.Points[0].Value != 8.333 {
+		t.Errorf("series = %+v", s)
+	}
+	if strings.Join(s.Tags, ",") != "account:123456789012,service:ec2,quota_code:L-1216C47A,region:us-east-1" {
+		t.Errorf("tags = %v", s.Tags)
+	}
+}
+
+func TestInfluxDBSinkLineProtocol(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v2/write" || q.Get("org") != "ops" || q.Get("bucket") != "quotas" || q.Get("precision") != "s" {
+			t.Errorf("request %s", r.URL)
+		}
+		if r.Header.Get("Authorization") != "Token influx-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		data, _ := io.ReadAll(r.Body)
+		got = string(data)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := &InfluxDBSink{URL: server.URL, Org: "ops", Bucket: "quotas", Token: "influx-token", Measurement: "aws_service_quota", Client: server.Client()}
+	report := newMetricsTestReport()
+	report.Quotas[0].Region = "us east,1" // exercises tag escaping
+	if err := sink.Publish(context.Background(), report); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := `aws_service_quota,account=123456789012,service=ec2,quota_code=L-1216C47A,region=us\ east\,1 limit=1152,usage=96,utilization=8.333 1700000000`
+	if got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+}
+
+func TestInfluxDBSinkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"code":"unauthorized"}`)
+	}))
+	defer server.Close()
+
+	sink := &InfluxDBSink{URL: server.URL, Org: "ops", Bucket: "quotas", Measurement: "aws_service_quota", Client: server.Client()}
+	err := sink.Publish(context.Background(), newMetricsTestReport())
+	if err == nil || err.Error() != `influxdb returned 401 Unauthorized: {"code":"unauthorized"}` {
+		t.Errorf("Publish error = %v", err)
+	}
+}
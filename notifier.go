@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Notifier delivers a report to a chat, paging or ticketing system
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, report *Report) error
+}
+
+// previewer is implemented by notifiers that can render their payload without sending it
+type previewer interface {
+	Preview(report *Report) ([]byte, error)
+}
+
+// notifyRoute sends a report, or the part of it a team owns, through that team's notifiers
+type notifyRoute struct {
+	Team      string // empty for the default destinations
+	Report    *Report
+	Notifiers []Notifier
+	EveryRun  bool // notify even when there are no alert changes
+}
+
+func (r notifyRoute) label(n Notifier) string {
+	if r.Team == "" {
+		return n.Name()
+	}
+	return r.Team + ": " + n.Name()
+}
+
+// previewAll writes every notifier's rendered payload instead of sending it
+func previewAll(w io.Writer, routes []notifyRoute) {
+	for _, r := range routes {
+		for _, n := range r.Notifiers {
+			fmt.Fprintf(w, "===== %s =====\n", r.label(n))
+			p, ok := n.(previewer)
+			if !ok {
+				fmt.Fprintln(w, "(no preview available)")
+				continue
+			}
+			payload, err := p.Preview(r.Report)
+			if err != nil {
+				fmt.Fprintf(w, "❌ %v\n", err)
+				continue
+			}
+			w.Write(payload)
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// notifyHTTPTimeout bounds every outbound notification request
+const notifyHTTPTimeout = 30 * time.Second
+
+func newNotifyHTTPClient() *http.Client {
+	return &http.Client{Timeout: notifyHTTPTimeout}
+}
+
+// notifyAll sends each route's report through its notifiers and returns how many failed.
+// Routes with tracked alert state but no changes are skipped, unless they run every time.
+func notifyAll(ctx context.Context, routes []notifyRoute) int {
+	failed := 0
+	for _, r := range routes {
+		if !r.EveryRun && r.Report.Events != nil && len(r.Report.Events) == 0 {
+			continue
+		}
+		for _, n := range r.Notifiers {
+			if err := n.Notify(ctx, r.Report); err != nil {
+				log.Printf("❌ Error notifying %s: %v", r.label(n), err)
+				failed++
+				continue
+			}
+			log.Printf("✅ Notified %s", r.label(n))
+		}
+	}
+	return failed
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// otelConfig selects the OTLP endpoint; standard OTEL_EXPORTER_OTLP_* variables (e.g., headers) also apply
+type otelConfig struct {
+	Endpoint string // host:port, or a full URL
+	Protocol string // grpc or http
+	Insecure bool
+	Interval time.Duration
+	Prefix   string // metric name prefix, as for the other metric sinks
+}
+
+// OTelSink exports the quota gauges of the latest report through the OpenTelemetry SDK
+type OTelSink struct {
+	endpoint string
+	prefix   string
+	provider *sdkmetric.MeterProvider
+	latest   atomic.Pointer[Report]
+}
+
+func newOTelExporter(ctx context.Context, c otelConfig) (sdkmetric.Exporter, error) {
+	isURL := strings.Contains(c.Endpoint, "://")
+	switch c.Protocol {
+	case "grpc":
+		opts := []otlpmetricgrpc.Option{}
+		if isURL {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(c.Endpoint))
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case "http":
+		opts := []otlpmetrichttp.Option{}
+		if isURL {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(c.Endpoint))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q (use grpc or http)", c.Protocol)
+	}
+}
+
+// NewOTelSink sets up a meter provider whose resource identifies the account and tool version
+func NewOTelSink(ctx context.Context, c otelConfig, accountID string) (*OTelSink, error) {
+	exporter, err := newOTelExporter(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP exporter: %v", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", "awsservicesquotafetcher"),
+		attribute.String("service.version", version),
+		attribute.String("cloud.provider", "aws"),
+		attribute.String("cloud.account.id", accountID),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error building OTel resource: %v", err)
+	}
+
+	sink := &OTelSink{endpoint: c.Endpoint, prefix: c.Prefix}
+	sink.provider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(c.Interval))),
+	)
+	if err := sink.registerGauges(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// registerGauges reports limit, usage and utilization of the latest report on every collection
+func (s *OTelSink) registerGauges() error {
+	meter := s.provider.Meter("github.com/Psalm-Albatross/awsservicesquotafetcher")
+	limit, err := meter.Float64ObservableGauge(s.prefix+".limit", metric.WithDescription("Applied quota value"))
+	if err != nil {
+		return err
+	}
+	usage, err := meter.Float64ObservableGauge(s.prefix+".usage", metric.WithDescription("Current usage of the quota"))
+	if err != nil {
+		return err
+	}
+	utilization, err := meter.Float64ObservableGauge(s.prefix+".utilization", metric.WithDescription("Usage as a percentage of the quota"), metric.WithUnit("%"))
+	if err != nil {
+		return err
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		report := s.latest.Load()
+		if report == nil {
+			return nil
+		}
+		for _, q := range report.Quotas {
+			tags := quotaTags(report, q)
+			attrs := make([]attribute.KeyValue, 0, len(tags))
+			for _, t := range tags {
+				attrs = append(attrs, attribute.String(t[0], t[1]))
+			}
+			set := metric.WithAttributes(attrs...)
+			o.ObserveFloat64(limit, q.Allocated, set)
+			o.ObserveFloat64(usage, q.Used, set)
+			o.ObserveFloat64(utilization, q.UtilizedPerc, set)
+		}
+		return nil
+	}, limit, usage, utilization)
+	return err
+}
+
+func (s *OTelSink) Name() string {
+	return "OTLP " + s.endpoint
+}
+
+// Publish makes the report the observed one and exports it right away
+func (s *OTelSink) Publish(ctx context.Context, report *Report) error {
+	s.latest.Store(report)
+	return s.provider.ForceFlush(ctx)
+}
+
+// Close flushes and stops the exporter
+func (s *OTelSink) Close(ctx context.Context) error {
+	return s.provider.Shutdown(ctx)
+}
+
+// runOTelCollector refetches quotas every interval and exports them until ctx ends, for serve mode
+func runOTelCollector(ctx context.Context, sink *OTelSink, interval time.Duration, collect func(context.Context) *Report) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report := collect(ctx)
+		if err := sink.Publish(ctx, report); err != nil {
+			log.Printf("❌ Error exporting OTel metrics: %v", err)
+		} else {
+			log.Printf("✅ Exported %d quotas to %s", len(report.Quotas), sink.Name())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
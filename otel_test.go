@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestOTelGaugesUseMetricsPrefix(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	sink := &OTelSink{prefix: "platform.quota", provider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))}
+	if err := sink.registerGauges(); err != nil {
+		t.Fatal(err)
+	}
+	sink.latest.Store(newMetricsTestReport())
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+			gauge, ok := m.Data.(metricdata.Gauge[float64])
+			if !ok || len(gauge.DataPoints) != 1 {
+				t.Errorf("%s: data %T with unexpected points", m.Name, m.Data)
+				continue
+			}
+			if v, _ := gauge.DataPoints[0].Attributes.Value("quota_code"); v.AsString() != "L-1216C47A" {
+				t.Errorf("%s: quota_code = %q", m.Name, v.AsString())
+			}
+		}
+	}
+	sort.Strings(names)
+	want := []string{"platform.quota.limit", "platform.quota.usage", "platform.quota.utilization"}
+	if len(names) != len(want) {
+		t.Fatalf("metrics %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("metrics %v, want %v", names, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+// Owner is a team that owns some quotas and where their alerts go.
+// A quota matches when every non-empty selector matches it.
+type Owner struct {
+	Name       string            `json:"name"`
+	Services   []string          `json:"services,omitempty"`
+	QuotaCodes []string          `json:"quota_codes,omitempty"`
+	Accounts   []string          `json:"accounts,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"` // tags on the applied quota in Service Quotas
+
+	SlackChannel        string   `json:"slack_channel,omitempty"`
+	SlackWebhookURL     string   `json:"slack_webhook_url,omitempty"`
+	TeamsWebhookURL     string   `json:"teams_webhook_url,omitempty"`
+	Email               []string `json:"email,omitempty"`
+	PagerDutyRoutingKey string   `json:"pagerduty_routing_key,omitempty"`
+}
+
+// Ownership maps quotas to their owning teams
+type Ownership struct {
+	Owners []Owner `json:"owners"`
+
+	quotaTags map[string]map[string]string // quota key → tags, filled by LoadTags
+}
+
+// LoadOwnership reads an ownership map and checks every owner selects something
+func LoadOwnership(path string) (*Ownership, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ownership Ownership
+	if err := json.Unmarshal(data, &ownership); err != nil {
+		return nil, fmt.Errorf("error parsing ownership map %s: %v", path, err)
+	}
+	for i, o := range ownership.Owners {
+		if o.Name == "" {
+			return nil, fmt.Errorf("owner #%d in %s has no name", i+1, path)
+		}
+		if len(o.Services)+len(o.QuotaCodes)+len(o.Accounts)+len(o.Tags) == 0 {
+			return nil, fmt.Errorf("owner %s in %s has no services, quota_codes, accounts or tags", o.Name, path)
+		}
+	}
+	return &ownership, nil
+}
+
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// specificity scores how precisely an owner selects a quota, 0 when it does not match.
+// A tag match outweighs a quota code match, which outweighs a service match, then an account match.
+func (o *Owner) specificity(accountID string, q QuotaInfo, tags map[string]string) int {
+	score := 0
+	if len(o.Tags) > 0 {
+		for k, v := range o.Tags {
+			if tags[k] != v {
+				return 0
+			}
+		}
+		score += 8
+	}
+	for _, sel := range []struct {
+		list   []string
+		value  string
+		weight int
+	}{
+		{o.QuotaCodes, q.QuotaCode, 4},
+		{o.Services, q.ServiceName, 2},
+		{o.Accounts, accountID, 1},
+	} {
+		if len(sel.list) == 0 {
+			continue
+		}
+		if !containsFold(sel.list, sel.value) {
+			return 0
+		}
+		score += sel.weight
+	}
+	return score
+}
+
+// Owner returns the most specific owner of a quota, the first listed on ties, or nil
+func (m *Ownership) Owner(report *Report, q QuotaInfo) *Owner {
+	var best *Owner
+	bestScore := 0
+	for i := range m.Owners {
+		if score := m.Owners[i].specificity(report.AccountID, q, m.quotaTags[quotaKey(report, q)]); score > bestScore {
+			best, bestScore = &m.Owners[i], score
+		}
+	}
+	return best
+}
+
+// subReport copies a report's metadata with only the selected quotas and their events
+func subReport(report *Report, keep func(QuotaInfo) bool) *Report {
+	sub := *report
+	sub.Quotas = nil
+	for _, q := range report.Quotas {
+		if keep(q) {
+			sub.Quotas = append(sub.Quotas, q)
+		}
+	}
+	if report.Events != nil {
+		sub.Events = []AlertEvent{}
+		for _, e := range report.Events {
+			if keep(e.Quota) {
+				sub.Events = append(sub.Events, e)
+			}
+		}
+	}
+	return &sub
+}
+
+// Split divides a report by owner; quotas nobody owns are returned separately.
+// Events count too, so a team hears about its quotas recovering after they were filtered out.
+func (m *Ownership) Split(report *Report) (map[*Owner]*Report, *Report) {
+	owners := map[string]*Owner{}
+	for _, q := range report.Quotas {
+		owners[quotaKey(report, q)] = m.Owner(report, q)
+	}
+	for _, e := range report.Events {
+		owners[e.Key] = m.Owner(report, e.Quota)
+	}
+	owned := map[*Owner]*Report{}
+	for i := range m.Owners {
+		o := &m.Owners[i]
+		sub := subReport(report, func(q QuotaInfo) bool { return owners[quotaKey(report, q)] == o })
+		if len(sub.Quotas) > 0 || len(sub.Events) > 0 {
+			owned[o] = sub
+		}
+	}
+	return owned, subReport(report, func(q QuotaInfo) bool { return owners[quotaKey(report, q)] == nil })
+}
+
+// quotaTagLister returns the tags of a quota ARN in a region
+type quotaTagLister func(ctx context.Context, region string, arn string) (map[string]string, error)
+
+// serviceQuotasTags lists quota tags with the Service Quotas API. Only applied quotas can
+// be tagged; quotas at their default have no ARN to tag and so have no tags.
+func serviceQuotasTags(cfg aws.Config) quotaTagLister {
+	clients := map[string]*servicequotas.Client{}
+	return func(ctx context.Context, region string, arn string) (map[string]string, error) {
+		client, ok := clients[region]
+		if !ok {
+			regionCfg := cfg.Copy()
+			regionCfg.Region = region
+			client = servicequotas.NewFromConfig(regionCfg)
+			clients[region] = client
+		}
+		output, err := client.ListTagsForResource(ctx, &servicequotas.ListTagsForResourceInput{ResourceARN: aws.String(arn)})
+		var notFound *types.NoSuchResourceException
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags := map[string]string{}
+		for _, tag := range output.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		return tags, nil
+	}
+}
+
+// quotaARN builds the Service Quotas ARN of an applied quota
+func quotaARN(accountID string, q QuotaInfo) string {
+	partition := "aws"
+	switch {
+	case strings.HasPrefix(q.Region, "cn-"):
+		partition = "aws-cn"
+	case strings.HasPrefix(q.Region, "us-gov-"):
+		partition = "aws-us-gov"
+	}
+	return fmt.Sprintf("arn:%s:servicequotas:%s:%s:%s/%s", partition, q.Region, accountID, q.ServiceName, q.QuotaCode)
+}
+
+// LoadTags looks up the tags of every quota in the report (one call per quota) when an
+// owner selects by tag. Lookups that fail are logged and leave the quota untagged.
+func (m *Ownership) LoadTags(ctx context.Context, report *Report, list quotaTagLister) {
+	needed := false
+	for _, o := range m.Owners {
+		needed = needed || len(o.Tags) > 0
+	}
+	if !needed {
+		return
+	}
+
+	m.quotaTags = map[string]map[string]string{}
+	quotas := append([]QuotaInfo(nil), report.Quotas...)
+	for _, e := range report.Events {
+		quotas = append(quotas, e.Quota)
+	}
+	for _, q := range quotas {
+		key := quotaKey(report, q)
+		if _, done := m.quotaTags[key]; done {
+			continue
+		}
+		tags, err := list(ctx, q.Region, quotaARN(report.AccountID, q))
+		if err != nil {
+			log.Printf("⚠️ Error listing tags of %s %s in %s: %v", q.ServiceName, q.QuotaCode, q.Region, err)
+		}
+		m.quotaTags[key] = tags
+	}
+}
+
+// ownerDefaults carries the global settings an owner's destinations inherit
+type ownerDefaults struct {
+	SlackToken       string
+	SlackAPIURL      string
+	SlackMaxQuotas   int
+	SlackFileFormat  string
+	TeamsMaxQuotas   int
+	Email            *EmailNotifier // SMTP settings; nil when --smtp-host is not set
+	PagerDutyURL     string
+	IncidentSeverity Severity
+	Incidents        []Notifier // global PagerDuty/Opsgenie, used by owners without their own routing key
+}
+
+// Notifiers builds the destinations configured for an owner
+func (o *Owner) Notifiers(d ownerDefaults, client func() *http.Client) ([]Notifier, error) {
+	var notifiers []Notifier
+	if o.SlackWebhookURL != "" {
+		notifiers = append(notifiers, &SlackWebhookNotifier{URL: o.SlackWebhookURL, MaxQuotas: d.SlackMaxQuotas, Client: client()})
+	}
+	if o.SlackChannel != "" {
+		if d.SlackToken == "" {
+			return nil, fmt.Errorf("owner %s has a slack_channel but --slack-token is not set", o.Name)
+		}
+		notifiers = append(notifiers, &SlackBotNotifier{
+			Token:      d.SlackToken,
+			Channel:    o.SlackChannel,
+			APIURL:     d.SlackAPIURL,
+			MaxQuotas:  d.SlackMaxQuotas,
+			FileFormat: d.SlackFileFormat,
+			Client:     client(),
+		})
+	}
+	if o.TeamsWebhookURL != "" {
+		notifiers = append(notifiers, &TeamsNotifier{URL: o.TeamsWebhookURL, MaxQuotas: d.TeamsMaxQuotas, Client: client()})
+	}
+	if len(o.Email) > 0 {
+		if d.Email == nil {
+			return nil, fmt.Errorf("owner %s has email recipients but --smtp-host is not set", o.Name)
+		}
+		// Warning recipients also get the digest announcing their quotas recovered
+		email := *d.Email
+		email.Recipients = map[Severity][]string{SeverityWarning: o.Email}
+		notifiers = append(notifiers, &email)
+	}
+	if o.PagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, &IncidentNotifier{
+			Label:       "PagerDuty",
+			API:         &PagerDutyEvents{RoutingKey: o.PagerDutyRoutingKey, URL: d.PagerDutyURL, Client: client()},
+			MinSeverity: d.IncidentSeverity,
+		})
+	}
+	if len(notifiers) == 0 {
+		return nil, fmt.Errorf("owner %s has no destinations", o.Name)
+	}
+	// Owned breaches must still page someone
+	if o.PagerDutyRoutingKey == "" {
+		notifiers = append(notifiers, d.Incidents...)
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOwnershipSplit(t *testing.T) {
+	lambda := QuotaInfo{ServiceName: "lambda", QuotaCode: "L-B99A9384", Region: "us-east-1", Allocated: 1000, Used: 900, UtilizedPerc: 90}
+	vcpus := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 100, Used: 85, UtilizedPerc: 85}
+	vpcs := QuotaInfo{ServiceName: "vpc", QuotaCode: "L-F678F1CE", Region: "us-east-1", Allocated: 5, Used: 1, UtilizedPerc: 20}
+	// Two quotas with equal values used to share an owner because Split keyed on the whole struct
+	tagged := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-34B43A08", Region: "us-east-1", Allocated: 100, Used: 85, UtilizedPerc: 85}
+	recovered := QuotaInfo{ServiceName: "lambda", QuotaCode: "L-2ACBD22F", Region: "us-east-1", Allocated: 75, Used: 70, UtilizedPerc: 93}
+
+	report := &Report{
+		AccountID:   "123456789012",
+		GeneratedAt: time.Now(),
+		Thresholds:  Thresholds{Warning: 80, Critical: 90},
+		Quotas:      []QuotaInfo{lambda, vcpus, vpcs, tagged},
+	}
+	report.Events = []AlertEvent{{Kind: AlertRecovered, Key: quotaKey(report, recovered), Quota: recovered, Severity: SeverityOK, Previous: SeverityCritical}}
+
+	ownership := &Ownership{Owners: []Owner{
+		{Name: "serverless", Services: []string{"lambda"}},
+		{Name: "compute", Services: []string{"EC2"}},
+		{Name: "spot", Tags: map[string]string{"team": "spot"}},
+	}}
+	ownership.LoadTags(context.Background(), report, func(ctx context.Context, region string, arn string) (map[string]string, error) {
+		if arn == "arn:aws:servicequotas:us-east-1:123456789012:ec2/L-34B43A08" {
+			return map[string]string{"team": "spot"}, nil
+		}
+		return nil, nil
+	})
+
+	owned, unowned := ownership.Split(report)
+	codes := func(r *Report) []string {
+		var out []string
+		for _, q := range r.Quotas {
+			out = append(out, q.QuotaCode)
+		}
+		return out
+	}
+	want := map[string][]string{"serverless": {"L-B99A9384"}, "compute": {"L-1216C47A"}, "spot": {"L-34B43A08"}}
+	for i := range ownership.Owners {
+		o := &ownership.Owners[i]
+		if got := codes(owned[o]); len(got) != 1 || got[0] != want[o.Name][0] {
+			t.Errorf("%s owns %v, want %v", o.Name, got, want[o.Name])
+		}
+	}
+	if got := codes(unowned); len(got) != 1 || got[0] != "L-F678F1CE" {
+		t.Errorf("unowned %v, want [L-F678F1CE]", got)
+	}
+	if events := owned[&ownership.Owners[0]].Events; len(events) != 1 || events[0].Quota.QuotaCode != "L-2ACBD22F" {
+		t.Errorf("serverless events %+v, want the recovery", events)
+	}
+}
+
+func TestOwnerEmailGetsRecoveries(t *testing.T) {
+	owner := &Owner{Name: "compute", Services: []string{"ec2"}, Email: []string{"compute@example.com"}}
+	notifiers, err := owner.Notifiers(ownerDefaults{Email: &EmailNotifier{Host: "smtp.example.com"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := notifiers[0].(*EmailNotifier)
+
+	q := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 100, Used: 10, UtilizedPerc: 10}
+	report := &Report{AccountID: "123456789012", Thresholds: Thresholds{Warning: 80, Critical: 90}, Quotas: []QuotaInfo{q}}
+	if to, _ := email.recipients(report); len(to) != 0 {
+		t.Errorf("all-OK digest went to %v", to)
+	}
+	report.Events = []AlertEvent{{Kind: AlertRecovered, Quota: q, Severity: SeverityOK, Previous: SeverityWarning}}
+	to, highest := email.recipients(report)
+	if len(to) != 1 || to[0] != "compute@example.com" || highest != SeverityOK {
+		t.Errorf("recovery digest went to %v at %s", to, highest)
+	}
+}
+
+func TestGlobalIncidentsPageForOwnersWithoutRoutingKey(t *testing.T) {
+	lambda := QuotaInfo{ServiceName: "lambda", QuotaCode: "L-B99A9384", Region: "us-east-1", Allocated: 1000, Used: 950, UtilizedPerc: 95}
+	vcpus := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 100, Used: 95, UtilizedPerc: 95}
+	report := &Report{
+		AccountID:   "123456789012",
+		GeneratedAt: time.Now(),
+		Thresholds:  Thresholds{Warning: 80, Critical: 90},
+		Quotas:      []QuotaInfo{lambda, vcpus},
+	}
+	ownership := &Ownership{Owners: []Owner{
+		{Name: "serverless", Services: []string{"lambda"}, SlackWebhookURL: "https://hooks.slack.com/services/T/B/X"},
+		{Name: "compute", Services: []string{"ec2"}, PagerDutyRoutingKey: "compute-key"},
+	}}
+	global := &fakeIncidents{}
+	defaults := ownerDefaults{
+		IncidentSeverity: SeverityCritical,
+		Incidents:        []Notifier{&IncidentNotifier{Label: "PagerDuty", API: global, MinSeverity: SeverityCritical}},
+	}
+
+	owned, unowned := ownership.Split(report)
+	if len(unowned.Quotas) != 0 {
+		t.Fatalf("unowned %v, want none", unowned.Quotas)
+	}
+	routed := map[string][]string{}
+	for i := range ownership.Owners {
+		o := &ownership.Owners[i]
+		notifiers, err := o.Notifiers(defaults, newNotifyHTTPClient)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range notifiers {
+			routed[o.Name] = append(routed[o.Name], n.Name())
+			if n == defaults.Incidents[0] {
+				if err := n.Notify(context.Background(), owned[o]); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+
+	if got := strings.Join(routed["serverless"], ","); got != "Slack webhook,PagerDuty" {
+		t.Errorf("serverless notifiers = %s, want its Slack plus the global PagerDuty", got)
+	}
+	if got := strings.Join(routed["compute"], ","); got != "PagerDuty" {
+		t.Errorf("compute notifiers = %s, want only its own PagerDuty", got)
+	}
+	if want := quotaKey(report, lambda); len(global.triggered) != 1 || global.triggered[0] != want {
+		t.Errorf("global incidents triggered %v, want [%s]", global.triggered, want)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRow is the typed Parquet schema of a quota
+type parquetRow struct {
+	Account      string    `parquet:"account,dict"`
+	Service      string    `parquet:"service,dict"`
+	QuotaCode    string    `parquet:"quota_code"`
+	QuotaName    string    `parquet:"quota_name"`
+	Region       string    `parquet:"region,dict"`
+	Allocated    float64   `parquet:"allocated"`
+	Used         float64   `parquet:"used"`
+	UtilizedPerc float64   `parquet:"utilized_perc"`
+	Adjustable   bool      `parquet:"adjustable"`
+	Global       bool      `parquet:"global"`
+	Severity     string    `parquet:"severity,dict"`
+	GeneratedAt  time.Time `parquet:"generated_at,timestamp(millisecond)"`
+}
+
+// writeParquet writes the quotas with snappy compression and the run metadata in the file footer
+func writeParquet(w io.Writer, report *Report) error {
+	writer := parquet.NewGenericWriter[parquetRow](w, parquet.Compression(&parquet.Snappy))
+	for k, v := range map[string]string{
+		"account_id":         report.AccountID,
+		"regions":            strings.Join(report.Regions, ","),
+		"generated_at":       report.GeneratedAt.UTC().Format(time.RFC3339),
+		"tool_version":       version,
+		"warning_threshold":  fmt.Sprint(report.Thresholds.Warning),
+		"critical_threshold": fmt.Sprint(report.Thresholds.Critical),
+	} {
+		writer.SetKeyValueMetadata(k, v)
+	}
+
+	rows := make([]parquetRow, 0, len(report.Quotas))
+	for _, q := range report.Quotas {
+		r := newQuotaRecord(report, q)
+		rows = append(rows, parquetRow{
+			Account:      r.Account,
+			Service:      r.Service,
+			QuotaCode:    r.QuotaCode,
+			QuotaName:    r.QuotaName,
+			Region:       r.Region,
+			Allocated:    r.Allocated,
+			Used:         r.Used,
+			UtilizedPerc: r.UtilizedPerc,
+			Adjustable:   r.Adjustable,
+			Global:       r.Global,
+			Severity:     r.Severity,
+			GeneratedAt:  r.GeneratedAt,
+		})
+	}
+	if _, err := writer.Write(rows); err != nil {
+		return fmt.Errorf("error writing parquet rows: %v", err)
+	}
+	return writer.Close()
+}
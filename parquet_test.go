@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestWriteParquetRoundTrip(t *testing.T) {
+	report := newTestReport(4)
+	report.Regions = []string{"us-east-1", "eu-west-1"}
+	report.Quotas[0].Adjustable = true
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Numbers and flags are typed columns, not strings
+	wantKinds := map[string]parquet.Kind{
+		"account":       parquet.ByteArray,
+		"quota_code":    parquet.ByteArray,
+		"allocated":     parquet.Double,
+		"used":          parquet.Double,
+		"utilized_perc": parquet.Double,
+		"adjustable":    parquet.Boolean,
+		"global":        parquet.Boolean,
+		"generated_at":  parquet.Int64,
+	}
+	fields := map[string]parquet.Field{}
+	for _, field := range f.Schema().Fields() {
+		fields[field.Name()] = field
+	}
+	for name, kind := range wantKinds {
+		field, ok := fields[name]
+		if !ok {
+			t.Errorf("column %s is missing", name)
+			continue
+		}
+		if got := field.Type().Kind(); got != kind {
+			t.Errorf("column %s is %s, want %s", name, got, kind)
+		}
+	}
+	if ts := fields["generated_at"].Type().LogicalType(); ts == nil || ts.Timestamp == nil || ts.Timestamp.Unit.Millis == nil {
+		t.Errorf("generated_at logical type = %v, want a millisecond timestamp", ts)
+	}
+
+	wantMetadata := map[string]string{
+		"account_id":         "123456789012",
+		"regions":            "us-east-1,eu-west-1",
+		"generated_at":       "2025-03-01T12:00:00Z",
+		"tool_version":       version,
+		"warning_threshold":  "80",
+		"critical_threshold": "90",
+	}
+	for key, want := range wantMetadata {
+		if got, ok := f.Lookup(key); !ok || got != want {
+			t.Errorf("footer %s = %q (present %v), want %q", key, got, ok, want)
+		}
+	}
+
+	reader := parquet.NewGenericReader[parquetRow](bytes.NewReader(buf.Bytes()))
+	defer reader.Close()
+	rows := make([]parquetRow, 10)
+	n, err := reader.Read(rows)
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if n != len(report.Quotas) {
+		t.Fatalf("read %d rows, want %d", n, len(report.Quotas))
+	}
+	first := rows[0]
+	if first.QuotaCode != "L-00000000" || first.Allocated != 100 || first.Used != 85 || first.UtilizedPerc != 85 ||
+		!first.Adjustable || first.Severity != "warning" || !first.GeneratedAt.Equal(report.GeneratedAt) {
+		t.Errorf("first row = %+v", first)
+	}
+}
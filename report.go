@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Severity classifies a quota's utilization against the alert thresholds
+type Severity int
+
+const (
+	SeverityOK Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	for _, candidate := range []Severity{SeverityOK, SeverityWarning, SeverityCritical} {
+		if candidate.String() == string(text) {
+			*s = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", text)
+}
+
+// Thresholds are the utilization percentages at which quotas become warnings or critical
+type Thresholds struct {
+	Warning  float64
+	Critical float64
+}
+
+// Severity returns the severity for a utilization percentage
+func (t Thresholds) Severity(utilized float64) Severity {
+	switch {
+	case utilized >= t.Critical:
+		return SeverityCritical
+	case utilized >= t.Warning:
+		return SeverityWarning
+	default:
+		return SeverityOK
+	}
+}
+
+// Report is one run's quotas plus the context notifiers and sinks need
+type Report struct {
+	AccountID   string
+	Regions     []string
+	GeneratedAt time.Time
+	Thresholds  Thresholds
+	Quotas      []QuotaInfo
+
+	// Errors are the service/region fetches that failed and are missing from Quotas
+	Errors []FetchError
+
+	// Events are the alert changes since the previous run; nil when alert state is not tracked
+	Events []AlertEvent
+}
+
+// FetchError records a service/region whose quotas could not be fetched
+type FetchError struct {
+	Service string
+	Region  string
+	Message string
+}
+
+// Severity returns the severity of a quota in this report
+func (r *Report) Severity(q QuotaInfo) Severity {
+	return r.Thresholds.Severity(q.UtilizedPerc)
+}
+
+// quotaKey identifies a quota across runs; alert state and incident APIs use it as the dedup key
+func quotaKey(report *Report, q QuotaInfo) string {
+	return fmt.Sprintf("%s/%s/%s/%s", report.AccountID, q.Region, q.ServiceName, q.QuotaCode)
+}
+
+// Breaching returns quotas at or above the warning threshold, most utilized first
+func (r *Report) Breaching() []QuotaInfo {
+	var breaching []QuotaInfo
+	for _, q := range r.Quotas {
+		if r.Severity(q) > SeverityOK {
+			breaching = append(breaching, q)
+		}
+	}
+	sort.SliceStable(breaching, func(i, j int) bool { return breaching[i].UtilizedPerc > breaching[j].UtilizedPerc })
+	return breaching
+}
+
+// ByService groups quotas by service, most utilized first, and returns the service names sorted
+func (r *Report) ByService() ([]string, map[string][]QuotaInfo) {
+	byService := map[string][]QuotaInfo{}
+	for _, q := range r.Quotas {
+		byService[q.ServiceName] = append(byService[q.ServiceName], q)
+	}
+	services := make([]string, 0, len(byService))
+	for service, quotas := range byService {
+		services = append(services, service)
+		sort.SliceStable(quotas, func(i, j int) bool { return quotas[i].UtilizedPerc > quotas[j].UtilizedPerc })
+	}
+	sort.Strings(services)
+	return services, byService
+}
+
+// SeverityCounts counts quotas per severity
+func (r *Report) SeverityCounts() map[Severity]int {
+	counts := map[Severity]int{}
+	for _, q := range r.Quotas {
+		counts[r.Severity(q)]++
+	}
+	return counts
+}
+
+// lookupAccountID returns the account ID of the caller's credentials
+func lookupAccountID(ctx context.Context, cfg aws.Config) (string, error) {
+	output, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("error resolving account ID: %v", err)
+	}
+	return aws.ToString(output.Account), nil
+}
+
+// resolveAccountID returns the account ID for reports, reusing the cache's lookup when enabled
+func resolveAccountID(ctx context.Context, cfg aws.Config, cache *QuotaCache) string {
+	var id string
+	var err error
+	if cache != nil {
+		id, err = cache.account(ctx, cfg)
+	} else {
+		id, err = lookupAccountID(ctx, cfg)
+	}
+	if err != nil {
+		log.Printf("⚠️ %v", err)
+		return "unknown"
+	}
+	return id
+}
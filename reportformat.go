@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportContentTypes maps each report format to its MIME type
+var reportContentTypes = map[string]string{
+	"csv":      "text/csv",
+	"html":     "text/html; charset=utf-8",
+	"json":     "application/x-ndjson",
+	"markdown": "text/markdown; charset=utf-8",
+	"parquet":  "application/vnd.apache.parquet",
+	"xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func reportFormatNames() string {
+	names := make([]string, 0, len(reportContentTypes))
+	for name := range reportContentTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// quotaRecord is one quota as written to JSON reports; flat and snake_case so Athena can query it
+type quotaRecord struct {
+	Account      string    `json:"account"`
+	Service      string    `json:"service"`
+	QuotaCode    string    `json:"quota_code"`
+	QuotaName    string    `json:"quota_name"`
+	Region       string    `json:"region"`
+	Allocated    float64   `json:"allocated"`
+	Used         float64   `json:"used"`
+	UtilizedPerc float64   `json:"utilized_perc"`
+	Adjustable   bool      `json:"adjustable"`
+	Global       bool      `json:"global"`
+	Severity     string    `json:"severity"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+func newQuotaRecord(report *Report, q QuotaInfo) quotaRecord {
+	return quotaRecord{
+		Account:      report.AccountID,
+		Service:      q.ServiceName,
+		QuotaCode:    q.QuotaCode,
+		QuotaName:    q.QuotaName,
+		Region:       q.Region,
+		Allocated:    q.Allocated,
+		Used:         q.Used,
+		UtilizedPerc: q.UtilizedPerc,
+		Adjustable:   q.Adjustable,
+		Global:       q.Global,
+		Severity:     report.Severity(q).String(),
+		GeneratedAt:  report.GeneratedAt.UTC(),
+	}
+}
+
+// writeReport encodes a report's quotas: csv uses the LoadCSV-compatible layout,
+// json writes one record per line, parquet a typed, snappy-compressed file,
+// html a standalone page, markdown tables for GitHub and xlsx a workbook with a sheet per service
+func writeReport(w io.Writer, format string, report *Report) error {
+	switch format {
+	case "csv":
+		return writeCSV(w, report.Quotas)
+	case "json":
+		encoder := json.NewEncoder(w)
+		for _, q := range report.Quotas {
+			if err := encoder.Encode(newQuotaRecord(report, q)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "parquet":
+		return writeParquet(w, report)
+	case "html":
+		return writeHTML(w, report)
+	case "markdown":
+		return writeMarkdown(w, report)
+	case "xlsx":
+		return writeXLSX(w, report)
+	default:
+		return fmt.Errorf("unsupported report format %q (use %s)", format, reportFormatNames())
+	}
+}
+
+// SaveReport writes a report to a local file in the given format
+func SaveReport(report *Report, outputPath string, format string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(file, format, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type s3Putter interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// S3Sink uploads each run's report to Hive-style partitioned keys
+// (prefix/account=/region=/date=/run-HHMMSS.ext) so Athena can query the history
+type S3Sink struct {
+	Bucket   string
+	Prefix   string
+	Format   string
+	KMSKeyID string // enables SSE-KMS when set
+	Client   s3Putter
+}
+
+// NewS3Sink creates a sink using cfg's region; endpoint overrides the service URL (e.g., a local stand-in)
+func NewS3Sink(cfg aws.Config, bucket string, prefix string, format string, kmsKeyID string, endpoint string) *S3Sink {
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return &S3Sink{Bucket: bucket, Prefix: prefix, Format: format, KMSKeyID: kmsKeyID, Client: client}
+}
+
+func (s *S3Sink) Name() string {
+	return "s3://" + path.Join(s.Bucket, s.Prefix)
+}
+
+// objectKey is where one region's part of a run is stored
+func (s *S3Sink) objectKey(report *Report, region string) string {
+	t := report.GeneratedAt.UTC()
+	return path.Join(s.Prefix,
+		"account="+report.AccountID,
+		"region="+region,
+		"date="+t.Format("2006-01-02"),
+		fmt.Sprintf("run-%s.%s", t.Format("150405"), s.Format))
+}
+
+// Publish writes one object per region so every partition holds only its own quotas
+func (s *S3Sink) Publish(ctx context.Context, report *Report) error {
+	if len(report.Quotas) == 0 {
+		log.Printf("ℹ️ No quotas in the report, skipped the upload to %s", s.Name())
+		return nil
+	}
+	byRegion := map[string][]QuotaInfo{}
+	for _, q := range report.Quotas {
+		byRegion[q.Region] = append(byRegion[q.Region], q)
+	}
+	regions := make([]string, 0, len(byRegion))
+	for region := range byRegion {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		part := *report
+		part.Quotas = byRegion[region]
+		var buf bytes.Buffer
+		if err := writeReport(&buf, s.Format, &part); err != nil {
+			return fmt.Errorf("error encoding report: %v", err)
+		}
+
+		key := s.objectKey(report, region)
+		input := &s3.PutObjectInput{
+			Bucket:      aws.String(s.Bucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(buf.Bytes()),
+			ContentType: aws.String(reportContentTypes[s.Format]),
+		}
+		if s.KMSKeyID != "" {
+			input.ServerSideEncryption = s3types.ServerSideEncryptionAwsKms
+			input.SSEKMSKeyId = aws.String(s.KMSKeyID)
+		}
+		if _, err := s.Client.PutObject(ctx, input); err != nil {
+			return fmt.Errorf("error uploading s3://%s/%s: %v", s.Bucket, key, err)
+		}
+	}
+	return nil
+}
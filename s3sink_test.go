@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeS3 records uploaded objects
+type fakeS3 struct {
+	puts   []*s3.PutObjectInput
+	bodies []string
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.puts = append(f.puts, params)
+	f.bodies = append(f.bodies, string(body))
+	return &s3.PutObjectOutput{}, nil
+}
+
+func TestS3SinkPartitionsByRegion(t *testing.T) {
+	report := newTestReport(4)
+	report.Regions = []string{"us-east-1", "eu-west-1"}
+	report.Quotas[1].Region = "eu-west-1"
+	report.Quotas[3].Region = "eu-west-1"
+
+	api := &fakeS3{}
+	sink := &S3Sink{Bucket: "quota-history", Prefix: "quotas/", Format: "json", KMSKeyID: "alias/quotas", Client: api}
+	if err := sink.Publish(context.Background(), report); err != nil {
+		t.Fatal(err)
+	}
+
+	wantKeys := []string{
+		"quotas/account=123456789012/region=eu-west-1/date=2025-03-01/run-120000.json",
+		"quotas/account=123456789012/region=us-east-1/date=2025-03-01/run-120000.json",
+	}
+	if len(api.puts) != len(wantKeys) {
+		t.Fatalf("got %d uploads, want %d", len(api.puts), len(wantKeys))
+	}
+	for i, put := range api.puts {
+		if got := aws.ToString(put.Key); got != wantKeys[i] {
+			t.Errorf("key %d = %q, want %q", i, got, wantKeys[i])
+		}
+		if aws.ToString(put.Bucket) != "quota-history" {
+			t.Errorf("bucket = %q", aws.ToString(put.Bucket))
+		}
+		if got := aws.ToString(put.ContentType); got != "application/x-ndjson" {
+			t.Errorf("content type = %q", got)
+		}
+		if put.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms || aws.ToString(put.SSEKMSKeyId) != "alias/quotas" {
+			t.Errorf("encryption = %s with key %q", put.ServerSideEncryption, aws.ToString(put.SSEKMSKeyId))
+		}
+		// Each partition only holds its own region's quotas
+		region := strings.TrimPrefix(strings.Split(wantKeys[i], "/")[2], "region=")
+		if lines := strings.Count(api.bodies[i], "\n"); lines != 2 || strings.Count(api.bodies[i], `"`+region+`"`) != 2 {
+			t.Errorf("object %d has %d lines for %s:\n%s", i, lines, region, api.bodies[i])
+		}
+	}
+}
+
+func TestS3SinkWithoutKMS(t *testing.T) {
+	api := &fakeS3{}
+	sink := &S3Sink{Bucket: "quota-history", Format: "csv", Client: api}
+	if err := sink.Publish(context.Background(), newTestReport(1)); err != nil {
+		t.Fatal(err)
+	}
+	put := api.puts[0]
+	if got := aws.ToString(put.Key); got != "account=123456789012/region=us-east-1/date=2025-03-01/run-120000.csv" {
+		t.Errorf("key = %q", got)
+	}
+	if put.ServerSideEncryption != "" || put.SSEKMSKeyId != nil {
+		t.Errorf("encryption set without a KMS key: %s %v", put.ServerSideEncryption, put.SSEKMSKeyId)
+	}
+	if got := aws.ToString(put.ContentType); got != "text/csv" {
+		t.Errorf("content type = %q", got)
+	}
+}
+
+func TestS3SinkEmptyReport(t *testing.T) {
+	var logs bytes.Buffer
+	previous := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(previous) })
+
+	api := &fakeS3{}
+	sink := &S3Sink{Bucket: "quota-history", Format: "json", Client: api}
+	if err := sink.Publish(context.Background(), newTestReport(0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.puts) != 0 {
+		t.Errorf("uploaded %d objects for an empty report", len(api.puts))
+	}
+	if !strings.Contains(logs.String(), "skipped the upload to s3://quota-history") {
+		t.Errorf("log = %q, want the skipped upload noted", logs.String())
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// quotaMatcher reports whether a quota matches a search and how well
+type quotaMatcher func(def quotaDefinition) (int, bool)
+
+// newQuotaMatcher builds a regex matcher, or a fuzzy matcher where every term must match
+func newQuotaMatcher(query string, useRegex bool) (quotaMatcher, error) {
+	if useRegex {
+		re, err := regexp.Compile("(?i)" + query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search regex: %v", err)
+		}
+		return func(def quotaDefinition) (int, bool) {
+			if re.MatchString(def.QuotaName) || re.MatchString(def.QuotaCode) || re.MatchString(def.ServiceCode) || re.MatchString(def.ServiceName) {
+				return 1, true
+			}
+			return 0, false
+		}, nil
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+	return func(def quotaDefinition) (int, bool) {
+		// The Service Quotas API exposes no quota descriptions, so match what it does return
+		haystack := strings.ToLower(def.QuotaName + " " + def.QuotaCode + " " + def.ServiceCode + " " + def.ServiceName)
+		score := 0
+		for _, term := range terms {
+			switch {
+			case strings.Contains(haystack, term):
+				score += 2
+			case fuzzyContains(haystack, term):
+				score++
+			default:
+				return 0, false
+			}
+		}
+		return score, true
+	}, nil
+}
+
+// fuzzyContains reports whether the runes of term appear in order in s
+func fuzzyContains(s string, term string) bool {
+	i := 0
+	runes := []rune(term)
+	for _, r := range s {
+		if i < len(runes) && r == runes[i] {
+			i++
+		}
+	}
+	return i == len(runes)
+}
+
+type searchResult struct {
+	quotaDefinition
+	score int
+}
+
+// searchQuotas matches quotas across the given services, best matches first
+func searchQuotas(ctx context.Context, cfg aws.Config, serviceCodes []string, match quotaMatcher, cache *QuotaCache) []searchResult {
+	var results []searchResult
+	for _, def := range listAllQuotaDefinitions(ctx, cfg, serviceCodes, cache) {
+		if score, ok := match(def); ok {
+			results = append(results, searchResult{quotaDefinition: def, score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		if results[i].ServiceCode != results[j].ServiceCode {
+			return results[i].ServiceCode < results[j].ServiceCode
+		}
+		return results[i].QuotaName < results[j].QuotaName
+	})
+	return results
+}
+
+func formatQuotaValue(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+// Search quotas by name or code across services
+func runSearch(ctx context.Context, cfg aws.Config, query string, services string, useRegex bool, cache *QuotaCache) {
+	match, err := newQuotaMatcher(query, useRegex)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	serviceCodes := splitList(services)
+	if len(serviceCodes) == 0 || services == "all" {
+		serviceCodes, err = resolveAllServices(ctx, cfg, []string{cfg.Region}, cache)
+		if err != nil {
+			log.Fatalf("❌ Error resolving services: %v", err)
+		}
+	}
+
+	log.Printf("🔍 Searching %d services for %q", len(serviceCodes), query)
+	results := searchQuotas(ctx, cfg, serviceCodes, match, cache)
+	if len(results) == 0 {
+		fmt.Printf("No quotas matching %q in region %s\n", query, cfg.Region)
+		os.Exit(0)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Service\tQuota Code\tAdjustable\tDefault\tApplied\tQuota Name")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", r.ServiceCode, r.QuotaCode, r.Adjustable, formatQuotaValue(r.DefaultValue), formatQuotaValue(r.AppliedValue), r.QuotaName)
+	}
+	w.Flush()
+	log.Printf("✅ Found %d quotas matching %q", len(results), query)
+	os.Exit(0)
+}
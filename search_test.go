@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestQuotaMatcherFields(t *testing.T) {
+	def := quotaDefinition{ServiceCode: "ec2", ServiceName: "Amazon Elastic Compute Cloud (Amazon EC2)", QuotaCode: "L-1216C47A", QuotaName: "Running On-Demand Standard instances"}
+	for _, query := range []string{"on-demand", "L-1216C47A", "ec2", "compute"} {
+		for _, useRegex := range []bool{false, true} {
+			match, err := newQuotaMatcher(query, useRegex)
+			if err != nil {
+				t.Fatalf("newQuotaMatcher(%q, %v): %v", query, useRegex, err)
+			}
+			if _, ok := match(def); !ok {
+				t.Errorf("query %q (regex %v) did not match", query, useRegex)
+			}
+		}
+	}
+
+	match, err := newQuotaMatcher("^ec2$", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := match(def); !ok {
+		t.Errorf("regex did not match the service code")
+	}
+}
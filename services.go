@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+)
+
+// serviceConcurrency bounds parallel Service Quotas calls to stay under throttling limits
+const serviceConcurrency = 4
+
+// serviceQuotasAPI is the part of the Service Quotas client that lists services and quotas
+type serviceQuotasAPI interface {
+	servicequotas.ListServicesAPIClient
+	servicequotas.ListAWSDefaultServiceQuotasAPIClient
+	servicequotas.ListServiceQuotasAPIClient
+}
+
+// newServiceQuotasClient creates the client for cfg's region; tests replace it with a fake
+var newServiceQuotasClient = func(cfg aws.Config) serviceQuotasAPI {
+	return servicequotas.NewFromConfig(cfg)
+}
+
+// serviceInfo describes a service known to Service Quotas
+type serviceInfo struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// listServices returns every service that has quotas, sorted by service code
+func listServices(ctx context.Context, cfg aws.Config, cache *QuotaCache) ([]serviceInfo, error) {
+	var services []serviceInfo
+	if cache.get(ctx, cfg, "services", &services) {
+		return services, nil
+	}
+
+	pages := servicequotas.NewListServicesPaginator(newServiceQuotasClient(cfg), &servicequotas.ListServicesInput{})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing services: %v", err)
+		}
+		for _, svc := range page.Services {
+			services = append(services, serviceInfo{
+				Code: aws.ToString(svc.ServiceCode),
+				Name: aws.ToString(svc.ServiceName),
+			})
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Code < services[j].Code })
+
+	cache.put(ctx, cfg, "services", services)
+	return services, nil
+}
+
+// resolveAllServices returns the sorted union of service codes across regions
+func resolveAllServices(ctx context.Context, cfg aws.Config, regions []string, cache *QuotaCache) ([]string, error) {
+	seen := map[string]bool{}
+	var codes []string
+	for _, region := range regions {
+		cfg.Region = region
+		services, err := listServices(ctx, cfg, cache)
+		if err != nil {
+			return nil, err
+		}
+		for _, svc := range services {
+			if !seen[svc.Code] {
+				seen[svc.Code] = true
+				codes = append(codes, svc.Code)
+			}
+		}
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// listAllQuotaDefinitions fetches quota definitions for many services in parallel.
+// Services that fail are logged and skipped; results are in service order.
+func listAllQuotaDefinitions(ctx context.Context, cfg aws.Config, serviceCodes []string, cache *QuotaCache) []quotaDefinition {
+	perService := make([][]quotaDefinition, len(serviceCodes))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, serviceConcurrency)
+	for i, code := range serviceCodes {
+		wg.Add(1)
+		go func(i int, code string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			defs, err := listQuotaDefinitions(ctx, cfg, code, cache)
+			if err != nil {
+				log.Printf("❌ Error fetching quotas for %s: %v", code, err)
+				return
+			}
+			perService[i] = defs
+		}(i, code)
+	}
+	wg.Wait()
+
+	var all []quotaDefinition
+	for _, defs := range perService {
+		all = append(all, defs...)
+	}
+	return all
+}
+
+// List valid AWS services
+func listValidServices(ctx context.Context, cfg aws.Config, cache *QuotaCache) {
+	services, err := listServices(ctx, cfg, cache)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	fmt.Printf("Valid AWS services in region %s (usage: quotas with a usage collector):\n", cfg.Region)
+	for _, svc := range services {
+		usage := ""
+		if len(usageCollectors[svc.Code]) > 0 {
+			usage = "usage"
+		}
+		fmt.Printf("  %-32s %-6s %s\n", svc.Code, usage, svc.Name)
+	}
+	os.Exit(0)
+}
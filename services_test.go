@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+// fakeServiceQuotas serves one region's services and quotas, two items per page
+type fakeServiceQuotas struct {
+	services []string
+	defaults map[string][]types.ServiceQuota // by service code
+	applied  map[string][]types.ServiceQuota
+	failing  map[string]bool
+}
+
+// fakePage returns items [token, token+2) and the token of the next page
+func fakePage[T any](items []T, token *string) ([]T, *string) {
+	start, _ := strconv.Atoi(aws.ToString(token))
+	end := min(start+2, len(items))
+	if end < len(items) {
+		return items[start:end], aws.String(strconv.Itoa(end))
+	}
+	return items[start:end], nil
+}
+
+func (f *fakeServiceQuotas) ListServices(ctx context.Context, params *servicequotas.ListServicesInput, optFns ...func(*servicequotas.Options)) (*servicequotas.ListServicesOutput, error) {
+	codes, next := fakePage(f.services, params.NextToken)
+	out := &servicequotas.ListServicesOutput{NextToken: next}
+	for _, code := range codes {
+		out.Services = append(out.Services, types.ServiceInfo{ServiceCode: aws.String(code), ServiceName: aws.String("Service " + code)})
+	}
+	return out, nil
+}
+
+func (f *fakeServiceQuotas) ListAWSDefaultServiceQuotas(ctx context.Context, params *servicequotas.ListAWSDefaultServiceQuotasInput, optFns ...func(*servicequotas.Options)) (*servicequotas.ListAWSDefaultServiceQuotasOutput, error) {
+	code := aws.ToString(params.ServiceCode)
+	if f.failing[code] {
+		return nil, fmt.Errorf("throttled")
+	}
+	quotas, next := fakePage(f.defaults[code], params.NextToken)
+	return &servicequotas.ListAWSDefaultServiceQuotasOutput{Quotas: quotas, NextToken: next}, nil
+}
+
+func (f *fakeServiceQuotas) ListServiceQuotas(ctx context.Context, params *servicequotas.ListServiceQuotasInput, optFns ...func(*servicequotas.Options)) (*servicequotas.ListServiceQuotasOutput, error) {
+	quotas, next := fakePage(f.applied[aws.ToString(params.ServiceCode)], params.NextToken)
+	return &servicequotas.ListServiceQuotasOutput{Quotas: quotas, NextToken: next}, nil
+}
+
+// useFakeServiceQuotas routes Service Quotas calls to a fake per region for the rest of the test
+func useFakeServiceQuotas(t *testing.T, regions map[string]*fakeServiceQuotas) {
+	t.Helper()
+	previous := newServiceQuotasClient
+	newServiceQuotasClient = func(cfg aws.Config) serviceQuotasAPI {
+		fake, ok := regions[cfg.Region]
+		if !ok {
+			t.Errorf("unexpected region %q", cfg.Region)
+			return &fakeServiceQuotas{}
+		}
+		return fake
+	}
+	t.Cleanup(func() { newServiceQuotasClient = previous })
+}
+
+func testServiceQuota(service string, code string, value float64) types.ServiceQuota {
+	return types.ServiceQuota{ServiceCode: aws.String(service), QuotaCode: aws.String(code), QuotaName: aws.String("Quota " + code), Value: aws.Float64(value)}
+}
+
+func TestListServicesPaginatesAndSorts(t *testing.T) {
+	useFakeServiceQuotas(t, map[string]*fakeServiceQuotas{
+		"us-east-1": {services: []string{"vpc", "ec2", "lambda", "acm", "s3"}},
+	})
+	services, err := listServices(context.Background(), aws.Config{Region: "us-east-1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, svc := range services {
+		codes = append(codes, svc.Code)
+	}
+	if want := []string{"acm", "ec2", "lambda", "s3", "vpc"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("services = %v, want %v", codes, want)
+	}
+	if services[0].Name != "Service acm" {
+		t.Errorf("name = %q", services[0].Name)
+	}
+}
+
+func TestResolveAllServicesUnionsRegions(t *testing.T) {
+	useFakeServiceQuotas(t, map[string]*fakeServiceQuotas{
+		"us-east-1": {services: []string{"ec2", "lambda", "s3"}},
+		"eu-west-1": {services: []string{"s3", "bedrock", "ec2"}},
+	})
+	codes, err := resolveAllServices(context.Background(), aws.Config{}, []string{"us-east-1", "eu-west-1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"bedrock", "ec2", "lambda", "s3"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("services = %v, want %v", codes, want)
+	}
+}
+
+func TestListAllQuotaDefinitions(t *testing.T) {
+	fake := &fakeServiceQuotas{
+		defaults: map[string][]types.ServiceQuota{
+			"ec2": {testServiceQuota("ec2", "L-1", 5), testServiceQuota("ec2", "L-2", 10), testServiceQuota("ec2", "L-3", 20)},
+			"s3":  {testServiceQuota("s3", "L-9", 100)},
+		},
+		applied: map[string][]types.ServiceQuota{
+			"ec2": {testServiceQuota("ec2", "L-2", 50)},
+		},
+		failing: map[string]bool{"lambda": true},
+	}
+	useFakeServiceQuotas(t, map[string]*fakeServiceQuotas{"us-east-1": fake})
+
+	// A failing service is skipped; the rest keep the order of the service list
+	defs := listAllQuotaDefinitions(context.Background(), aws.Config{Region: "us-east-1"}, []string{"s3", "lambda", "ec2"}, nil)
+	var got []string
+	for _, def := range defs {
+		got = append(got, fmt.Sprintf("%s/%s=%v", def.ServiceCode, def.QuotaCode, def.Value()))
+	}
+	// Applied quotas come first with their default kept, then quotas only known by their default
+	want := []string{"s3/L-9=100", "ec2/L-2=50", "ec2/L-1=5", "ec2/L-3=20"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("definitions = %v, want %v", got, want)
+	}
+	if defs[1].DefaultValue == nil || *defs[1].DefaultValue != 10 {
+		t.Errorf("applied quota lost its default: %+v", defs[1])
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// Sink receives every quota of every run, unlike notifiers which only see alert changes
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, report *Report) error
+}
+
+// publishAll sends the report to every sink and returns how many failed
+func publishAll(ctx context.Context, sinks []Sink, report *Report) int {
+	failed := 0
+	for _, s := range sinks {
+		if err := s.Publish(ctx, report); err != nil {
+			log.Printf("❌ Error publishing to %s: %v", s.Name(), err)
+			failed++
+			continue
+		}
+		log.Printf("✅ Published to %s", s.Name())
+	}
+	return failed
+}
+
+// sinkCloser is implemented by sinks that must flush or stop background work before exit
+type sinkCloser interface {
+	Close(ctx context.Context) error
+}
+
+func closeSinks(ctx context.Context, sinks []Sink) {
+	for _, s := range sinks {
+		if c, ok := s.(sinkCloser); ok {
+			if err := c.Close(ctx); err != nil {
+				log.Printf("⚠️ Error closing %s: %v", s.Name(), err)
+			}
+		}
+	}
+}
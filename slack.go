@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Slack Block Kit limits; messages beyond them are rejected rather than truncated
+const (
+	slackMaxBlocks      = 50
+	slackMaxSectionText = 3000
+	slackMaxHeaderText  = 150
+	slackMaxMessageText = 40000
+)
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackMessage struct {
+	ResponseType string       `json:"response_type,omitempty"` // slash command replies only
+	Text         string       `json:"text"`
+	Blocks       []slackBlock `json:"blocks,omitempty"`
+}
+
+func slackHeader(text string) slackBlock {
+	return slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: truncate(text, slackMaxHeaderText)}}
+}
+
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncate(text, slackMaxSectionText)}}
+}
+
+func slackContext(text string) slackBlock {
+	return slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: text}}}
+}
+
+// slackEscape escapes the characters Slack treats as markup in mrkdwn text
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+func severityEmoji(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "🔴"
+	case SeverityWarning:
+		return "🟠"
+	default:
+		return "🟢"
+	}
+}
+
+// slackSummary is the one-line plain-text summary used for notifications and fallbacks
+func slackSummary(report *Report) string {
+	counts := report.SeverityCounts()
+	return fmt.Sprintf("AWS quota report for %s: %d critical, %d warning, %d ok",
+		report.AccountID, counts[SeverityCritical], counts[SeverityWarning], counts[SeverityOK])
+}
+
+func slackQuotaLine(report *Report, q QuotaInfo) string {
+	return fmt.Sprintf("%s `%6.2f%%` *%s* %s — %s (`%s`) %.0f / %.0f",
+		severityEmoji(report.Severity(q)), q.UtilizedPerc, slackEscape(q.ServiceName), q.Region,
+		slackEscape(q.QuotaName), q.QuotaCode, q.Used, q.Allocated)
+}
+
+// slackLineSections packs lines into as few sections as the text limit allows
+func slackLineSections(lines []string) []slackBlock {
+	var blocks []slackBlock
+	var section strings.Builder
+	for _, line := range lines {
+		if section.Len() > 0 && section.Len()+len(line)+1 > slackMaxSectionText {
+			blocks = append(blocks, slackSection(section.String()))
+			section.Reset()
+		}
+		if section.Len() > 0 {
+			section.WriteString("\n")
+		}
+		section.WriteString(line)
+	}
+	if section.Len() > 0 {
+		blocks = append(blocks, slackSection(section.String()))
+	}
+	return blocks
+}
+
+func slackQuotaSections(report *Report, quotas []QuotaInfo) []slackBlock {
+	lines := make([]string, 0, len(quotas))
+	for _, q := range quotas {
+		lines = append(lines, slackQuotaLine(report, q))
+	}
+	return slackLineSections(lines)
+}
+
+// buildSlackReport renders a report as Block Kit messages, split to respect Slack's block limit
+func buildSlackReport(report *Report, maxQuotas int) []slackMessage {
+	counts := report.SeverityCounts()
+	blocks := []slackBlock{
+		slackHeader("AWS Service Quota Report"),
+		slackSection(fmt.Sprintf("🔴 *Critical:* %d    🟠 *Warning:* %d    🟢 *OK:* %d\nThresholds: warning ≥ %.0f%%, critical ≥ %.0f%%",
+			counts[SeverityCritical], counts[SeverityWarning], counts[SeverityOK],
+			report.Thresholds.Warning, report.Thresholds.Critical)),
+		slackContext(fmt.Sprintf("Account *%s* • Regions %s • Run %s",
+			report.AccountID, strings.Join(report.Regions, ", "), report.GeneratedAt.UTC().Format(time.RFC1123))),
+		{Type: "divider"},
+	}
+
+	if len(report.Events) > 0 {
+		var lines []string
+		for _, e := range report.Events {
+			lines = append(lines, fmt.Sprintf("*%s* (%s → %s): %s", e.Kind.Label(), e.Previous, e.Severity, slackQuotaLine(report, e.Quota)))
+		}
+		blocks = append(blocks, slackSection(fmt.Sprintf("*Alert changes (%d)*", len(report.Events))))
+		blocks = append(blocks, slackLineSections(lines)...)
+		blocks = append(blocks, slackBlock{Type: "divider"})
+	}
+
+	breaching := report.Breaching()
+	if len(breaching) == 0 {
+		blocks = append(blocks, slackSection(fmt.Sprintf("✅ No quotas at or above %.0f%% utilization", report.Thresholds.Warning)))
+	} else {
+		shown := breaching
+		if maxQuotas > 0 && len(shown) > maxQuotas {
+			shown = shown[:maxQuotas]
+		}
+		blocks = append(blocks, slackSection(fmt.Sprintf("*Top %d breaching quotas*", len(shown))))
+		blocks = append(blocks, slackQuotaSections(report, shown)...)
+		if hidden := len(breaching) - len(shown); hidden > 0 {
+			blocks = append(blocks, slackContext(fmt.Sprintf("…and %d more breaching quotas", hidden)))
+		}
+	}
+
+	return splitSlackBlocks(slackSummary(report), blocks)
+}
+
+func slackBlockTextLen(b slackBlock) int {
+	n := 0
+	if b.Text != nil {
+		n += len(b.Text.Text)
+	}
+	for _, e := range b.Elements {
+		n += len(e.Text)
+	}
+	return n
+}
+
+// splitSlackBlocks chunks blocks into messages within Slack's block and text limits,
+// marking every message after the first as a continuation
+func splitSlackBlocks(fallback string, blocks []slackBlock) []slackMessage {
+	var messages []slackMessage
+	for len(blocks) > 0 {
+		msg := slackMessage{Text: fallback}
+		if len(messages) > 0 {
+			msg.Blocks = append(msg.Blocks, slackContext(fmt.Sprintf("_AWS Service Quota Report (continued, part %d)_", len(messages)+1)))
+		}
+		textLen := len(fallback)
+		for _, b := range msg.Blocks {
+			textLen += slackBlockTextLen(b)
+		}
+
+		n := 0
+		for n < len(blocks) && len(msg.Blocks) < slackMaxBlocks {
+			size := slackBlockTextLen(blocks[n])
+			if len(msg.Blocks) > 0 && textLen+size > slackMaxMessageText {
+				break
+			}
+			msg.Blocks = append(msg.Blocks, blocks[n])
+			textLen += size
+			n++
+		}
+		blocks = blocks[n:]
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// SlackWebhookNotifier posts Block Kit reports to a Slack incoming webhook
+type SlackWebhookNotifier struct {
+	URL       string
+	MaxQuotas int
+	Client    *http.Client
+}
+
+func (n *SlackWebhookNotifier) Name() string {
+	return "Slack webhook"
+}
+
+func (n *SlackWebhookNotifier) Notify(ctx context.Context, report *Report) error {
+	for _, msg := range buildSlackReport(report, n.MaxQuotas) {
+		if err := n.post(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *SlackWebhookNotifier) Preview(report *Report) ([]byte, error) {
+	return json.MarshalIndent(buildSlackReport(report, n.MaxQuotas), "", "  ")
+}
+
+func (n *SlackWebhookNotifier) post(ctx context.Context, msg slackMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshaling Slack message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Webhooks answer "ok" on success and a short error code such as invalid_blocks otherwise
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	// Slash command response URLs answer with JSON instead
+	var result slackAPIResponse
+	answer := strings.TrimSpace(string(body))
+	if answer != "ok" && (json.Unmarshal(body, &result) != nil || !result.OK) {
+		return fmt.Errorf("slack webhook did not accept the message: %s", answer)
+	}
+	return nil
+}
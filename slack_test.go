@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestReport returns a report with n quotas spread over a few services, all breaching
+func newTestReport(n int) *Report {
+	report := &Report{
+		AccountID:   "123456789012",
+		Regions:     []string{"us-east-1"},
+		GeneratedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Thresholds:  Thresholds{Warning: 80, Critical: 90},
+	}
+	for i := 0; i < n; i++ {
+		report.Quotas = append(report.Quotas, QuotaInfo{
+			ServiceName:  fmt.Sprintf("Service %d", i%3),
+			QuotaCode:    fmt.Sprintf("L-%08d", i),
+			QuotaName:    fmt.Sprintf("Quota %d with a reasonably long name to fill Slack sections", i),
+			Region:       "us-east-1",
+			Allocated:    100,
+			Used:         float64(85 + i%15),
+			UtilizedPerc: float64(85 + i%15),
+		})
+	}
+	return report
+}
+
+func TestSplitSlackBlocksLimits(t *testing.T) {
+	var blocks []slackBlock
+	for i := 0; i < 3*slackMaxBlocks; i++ {
+		blocks = append(blocks, slackSection(strings.Repeat("x", 2000)))
+	}
+	messages := splitSlackBlocks("fallback", blocks)
+	if len(messages) < 3 {
+		t.Fatalf("got %d messages, want at least 3", len(messages))
+	}
+
+	total := 0
+	for i, msg := range messages {
+		if len(msg.Blocks) > slackMaxBlocks {
+			t.Errorf("message %d has %d blocks, limit is %d", i, len(msg.Blocks), slackMaxBlocks)
+		}
+		textLen := len(msg.Text)
+		for _, b := range msg.Blocks {
+			textLen += slackBlockTextLen(b)
+		}
+		if textLen > slackMaxMessageText {
+			t.Errorf("message %d has %d characters of text, limit is %d", i, textLen, slackMaxMessageText)
+		}
+		if i > 0 {
+			if msg.Blocks[0].Type != "context" || !strings.Contains(msg.Blocks[0].Elements[0].Text, fmt.Sprintf("part %d", i+1)) {
+				t.Errorf("message %d does not start with a continuation marker", i)
+			}
+			total += len(msg.Blocks) - 1
+		} else {
+			total += len(msg.Blocks)
+		}
+	}
+	if total != len(blocks) {
+		t.Errorf("messages carry %d blocks, want %d", total, len(blocks))
+	}
+}
+
+func TestBuildSlackReportTruncatesQuotaList(t *testing.T) {
+	messages := buildSlackReport(newTestReport(30), 10)
+	var text strings.Builder
+	for _, msg := range messages {
+		for _, b := range msg.Blocks {
+			if b.Text != nil {
+				text.WriteString(b.Text.Text)
+			}
+			for _, e := range b.Elements {
+				text.WriteString(e.Text)
+			}
+		}
+	}
+	if !strings.Contains(text.String(), "*Top 10 breaching quotas*") || !strings.Contains(text.String(), "…and 20 more breaching quotas") {
+		t.Errorf("report does not cap the quota list:\n%s", text.String())
+	}
+}
+
+func TestSlackWebhookNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"accepted", http.StatusOK, "ok", ""},
+		{"accepted as JSON", http.StatusOK, `{"ok":true}`, ""},
+		{"ok false", http.StatusOK, `{"ok":false,"error":"expired_url"}`, `slack webhook did not accept the message: {"ok":false,"error":"expired_url"}`},
+		{"rejected", http.StatusBadRequest, "invalid_blocks", "slack webhook returned 400 Bad Request: invalid_blocks"},
+		{"not ok", http.StatusOK, "no_text", "slack webhook did not accept the message: no_text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			n := &SlackWebhookNotifier{URL: server.URL, Client: server.Client()}
+			err := n.Notify(context.Background(), newTestReport(3))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Notify: %v", err)
+				}
+				if requests != 1 {
+					t.Errorf("got %d requests, want 1", requests)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Notify error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
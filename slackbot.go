@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const slackDefaultAPIURL = "https://slack.com/api/"
+
+// slackAPIResponse holds the fields this tool reads from Slack Web API responses
+type slackAPIResponse struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error"`
+	Warning   string `json:"warning"`
+	Channel   string `json:"channel"`
+	TS        string `json:"ts"`
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// slackLegacyPushTarget maps a deprecated --push-data-to-slack URL, such as
+// https://slack.com/api/chat.postMessage?channel=C0123456789, to a Web API base URL and channel
+func slackLegacyPushTarget(rawURL string) (apiURL string, channel string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid Slack URL %q", rawURL)
+	}
+	channel = u.Query().Get("channel")
+	if i := strings.Index(u.Path, "/api/"); i >= 0 {
+		u.Path = u.Path[:i+len("/api/")]
+	} else {
+		u.Path = "/api/"
+	}
+	u.RawQuery = ""
+	return u.String(), channel, nil
+}
+
+// SlackBotNotifier posts reports through the Slack Web API with a bot token: the summary
+// goes to the channel, per-service detail and the full report file go into its thread
+type SlackBotNotifier struct {
+	Token      string
+	Channel    string
+	APIURL     string
+	MaxQuotas  int
+	FileFormat string // csv or json; empty skips the upload
+	Client     *http.Client
+}
+
+func (n *SlackBotNotifier) Name() string {
+	return "Slack channel " + n.Channel
+}
+
+func (n *SlackBotNotifier) Notify(ctx context.Context, report *Report) error {
+	var channelID, threadTS string
+	for _, msg := range buildSlackReport(report, n.MaxQuotas) {
+		resp, err := n.postMessage(ctx, msg, threadTS)
+		if err != nil {
+			return err
+		}
+		if threadTS == "" {
+			channelID, threadTS = resp.Channel, resp.TS
+		}
+	}
+
+	for _, msg := range buildSlackServiceDetails(report, n.MaxQuotas) {
+		if _, err := n.postMessage(ctx, msg, threadTS); err != nil {
+			return err
+		}
+	}
+
+	if n.FileFormat != "" {
+		return n.uploadReport(ctx, report, channelID, threadTS)
+	}
+	return nil
+}
+
+// slackPreviewThread stands in for the timestamp of the first message, which replies thread under
+const slackPreviewThread = "<first message>"
+
+// Preview renders the chat.postMessage payloads in order: the report, then its thread replies
+func (n *SlackBotNotifier) Preview(report *Report) ([]byte, error) {
+	type post struct {
+		Channel  string `json:"channel"`
+		ThreadTS string `json:"thread_ts,omitempty"`
+		slackMessage
+	}
+	var posts []post
+	for _, msg := range append(buildSlackReport(report, n.MaxQuotas), buildSlackServiceDetails(report, n.MaxQuotas)...) {
+		threadTS := ""
+		if len(posts) > 0 {
+			threadTS = slackPreviewThread
+		}
+		posts = append(posts, post{n.Channel, threadTS, msg})
+	}
+	payload, err := json.MarshalIndent(posts, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if n.FileFormat != "" {
+		payload = append(payload, fmt.Sprintf("\nfile upload: %s", slackReportFilename(report, n.FileFormat))...)
+	}
+	return payload, nil
+}
+
+// buildSlackServiceDetails renders one thread reply per service with breaching quotas
+func buildSlackServiceDetails(report *Report, maxQuotas int) []slackMessage {
+	byService := map[string][]QuotaInfo{}
+	for _, q := range report.Breaching() {
+		byService[q.ServiceName] = append(byService[q.ServiceName], q)
+	}
+	services := make([]string, 0, len(byService))
+	for service := range byService {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	var messages []slackMessage
+	for _, service := range services {
+		quotas := byService[service]
+		blocks := []slackBlock{slackSection(fmt.Sprintf("*%s* — %d quotas at or above %.0f%%", slackEscape(service), len(quotas), report.Thresholds.Warning))}
+		shown := quotas
+		if maxQuotas > 0 && len(shown) > maxQuotas {
+			shown = shown[:maxQuotas]
+		}
+		blocks = append(blocks, slackQuotaSections(report, shown)...)
+		if hidden := len(quotas) - len(shown); hidden > 0 {
+			blocks = append(blocks, slackContext(fmt.Sprintf("…and %d more, see the attached report", hidden)))
+		}
+		messages = append(messages, splitSlackBlocks(fmt.Sprintf("%s quota details", service), blocks)...)
+	}
+	return messages
+}
+
+func (n *SlackBotNotifier) postMessage(ctx context.Context, msg slackMessage, threadTS string) (*slackAPIResponse, error) {
+	payload, err := json.Marshal(struct {
+		Channel  string `json:"channel"`
+		ThreadTS string `json:"thread_ts,omitempty"`
+		slackMessage
+	}{n.Channel, threadTS, msg})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling Slack message: %v", err)
+	}
+	return n.call(ctx, "chat.postMessage", "application/json; charset=utf-8", payload)
+}
+
+func slackReportFilename(report *Report, format string) string {
+	return fmt.Sprintf("quota-report-%s-%s.%s", report.AccountID, report.GeneratedAt.UTC().Format("20060102-150405"), format)
+}
+
+// uploadReport attaches the full report using Slack's external upload flow
+func (n *SlackBotNotifier) uploadReport(ctx context.Context, report *Report, channelID string, threadTS string) error {
+	var buf bytes.Buffer
+	switch n.FileFormat {
+	case "csv":
+		if err := writeCSV(&buf, report.Quotas); err != nil {
+			return fmt.Errorf("error encoding report: %v", err)
+		}
+	case "json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report.Quotas); err != nil {
+			return fmt.Errorf("error encoding report: %v", err)
+		}
+	default:
+		return fmt.Errorf("unsupported Slack file format %q", n.FileFormat)
+	}
+	filename := slackReportFilename(report, n.FileFormat)
+
+	// Step 1: reserve an upload URL
+	form := url.Values{"filename": {filename}, "length": {strconv.Itoa(buf.Len())}}
+	reserved, err := n.call(ctx, "files.getUploadURLExternal", "application/x-www-form-urlencoded", []byte(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	// Step 2: send the bytes to the reserved URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reserved.UploadURL, &buf)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error uploading report file: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error uploading report file: %s", resp.Status)
+	}
+
+	// Step 3: share the file into the report thread
+	files, err := json.Marshal([]map[string]string{{"id": reserved.FileID, "title": filename}})
+	if err != nil {
+		return fmt.Errorf("error marshaling file list: %v", err)
+	}
+	form = url.Values{"files": {string(files)}, "channel_id": {channelID}}
+	if threadTS != "" {
+		form.Set("thread_ts", threadTS)
+	}
+	_, err = n.call(ctx, "files.completeUploadExternal", "application/x-www-form-urlencoded", []byte(form.Encode()))
+	return err
+}
+
+// call invokes a Web API method; Slack reports most failures as HTTP 200 with ok:false
+func (n *SlackBotNotifier) call(ctx context.Context, method string, contentType string, body []byte) (*slackAPIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(n.APIURL, "/")+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+n.Token)
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if retry := resp.Header.Get("Retry-After"); retry != "" {
+			return nil, fmt.Errorf("slack %s rate limited, retry after %ss", method, retry)
+		}
+		return nil, fmt.Errorf("slack %s returned %s", method, resp.Status)
+	}
+
+	var result slackAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding Slack %s response: %v", method, err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("slack %s failed: %s", method, result.Error)
+	}
+	return &result, nil
+}
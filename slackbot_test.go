@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlackLegacyPushTarget(t *testing.T) {
+	tests := []struct {
+		url, apiURL, channel string
+		wantErr              bool
+	}{
+		{"https://slack.com/api/chat.postMessage?channel=C0123456789", "https://slack.com/api/", "C0123456789", false},
+		{"https://slack.example.com/api/chat.postMessage", "https://slack.example.com/api/", "", false},
+		{"https://hooks.slack.com/services/T000/B000/XXXX", "https://hooks.slack.com/api/", "", false},
+		{"not a url", "", "", true},
+	}
+	for _, tt := range tests {
+		apiURL, channel, err := slackLegacyPushTarget(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("slackLegacyPushTarget(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if apiURL != tt.apiURL || channel != tt.channel {
+			t.Errorf("slackLegacyPushTarget(%q) = %q, %q, want %q, %q", tt.url, apiURL, channel, tt.apiURL, tt.channel)
+		}
+	}
+}
+
+func TestSlackBotNotifierThreadsReplies(t *testing.T) {
+	var threads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat.postMessage" {
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-test" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		var msg struct {
+			Channel  string `json:"channel"`
+			ThreadTS string `json:"thread_ts"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		threads = append(threads, msg.ThreadTS)
+		fmt.Fprint(w, `{"ok":true,"channel":"C0123456789","ts":"1700000000.000100"}`)
+	}))
+	defer server.Close()
+
+	n := &SlackBotNotifier{Token: "xoxb-test", Channel: "#quotas", APIURL: server.URL + "/api/", Client: server.Client()}
+	if err := n.Notify(context.Background(), newTestReport(6)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	// One summary plus a reply for each of the three services
+	if len(threads) != 4 || threads[0] != "" {
+		t.Fatalf("thread_ts per message = %q", threads)
+	}
+	for _, ts := range threads[1:] {
+		if ts != "1700000000.000100" {
+			t.Errorf("reply posted outside the report thread: %q", ts)
+		}
+	}
+}
+
+func TestSlackBotNotifierPreview(t *testing.T) {
+	n := &SlackBotNotifier{Token: "xoxb-test", Channel: "#quotas", FileFormat: "csv"}
+	var out strings.Builder
+	previewAll(&out, []notifyRoute{{Report: newTestReport(6), Notifiers: []Notifier{n}}})
+	text := out.String()
+	if strings.Contains(text, "no preview available") {
+		t.Fatalf("bot mode has no preview:\n%s", text)
+	}
+
+	payload, err := n.Preview(newTestReport(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, file, _ := strings.Cut(string(payload), "\nfile upload: ")
+	var posts []struct {
+		Channel  string       `json:"channel"`
+		ThreadTS string       `json:"thread_ts"`
+		Blocks   []slackBlock `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(body), &posts); err != nil {
+		t.Fatalf("preview is not the JSON posts: %v\n%s", err, body)
+	}
+	// Same messages Notify sends: one summary plus a reply for each of the three services
+	if len(posts) != 4 || posts[0].ThreadTS != "" || posts[0].Channel != "#quotas" || len(posts[0].Blocks) == 0 {
+		t.Fatalf("posts = %+v", posts)
+	}
+	for _, p := range posts[1:] {
+		if p.ThreadTS != slackPreviewThread {
+			t.Errorf("reply thread_ts = %q", p.ThreadTS)
+		}
+	}
+	if file != "quota-report-123456789012-20250301-120000.csv" {
+		t.Errorf("file upload = %q", file)
+	}
+}
+
+func TestSlackBotNotifierErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		header  string
+		body    string
+		wantErr string
+	}{
+		{"not ok", http.StatusOK, "", `{"ok":false,"error":"channel_not_found"}`, "slack chat.postMessage failed: channel_not_found"},
+		{"rate limited", http.StatusTooManyRequests, "30", "", "slack chat.postMessage rate limited, retry after 30s"},
+		{"server error", http.StatusInternalServerError, "", "", "slack chat.postMessage returned 500 Internal Server Error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			n := &SlackBotNotifier{Token: "xoxb-test", Channel: "C0123456789", APIURL: server.URL, Client: server.Client()}
+			err := n.Notify(context.Background(), newTestReport(1))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Notify error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
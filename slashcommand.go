@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// slackSignatureMaxAge rejects replayed slash command requests
+	slackSignatureMaxAge = 5 * time.Minute
+	slashLookupTimeout   = 2 * time.Minute
+	slashMaxQuotas       = 25
+	slashUsage           = "Usage: `/quota <service> [region] [quota code or name]`, e.g. `/quota ec2 us-west-2 L-1216C47A`"
+)
+
+// verifySlackSignature checks the v0 request signature Slack sends with every slash command
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return fmt.Errorf("request timestamp is too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// slashQuery is a parsed "/quota <service> [region] [quota]" command
+type slashQuery struct {
+	Service string
+	Region  string
+	Quota   string // quota code or case-insensitive name fragment; empty for all
+}
+
+func looksLikeRegion(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 {
+		return false
+	}
+	_, err := strconv.Atoi(parts[len(parts)-1])
+	return err == nil
+}
+
+// slashNamePattern limits services and regions to what AWS uses, since both end up in cache paths
+var slashNamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+func parseSlashQuery(text string, defaultRegion string) (slashQuery, error) {
+	words := strings.Fields(text)
+	if len(words) == 0 || strings.EqualFold(words[0], "help") {
+		return slashQuery{}, fmt.Errorf("%s", slashUsage)
+	}
+	query := slashQuery{Service: strings.ToLower(words[0]), Region: defaultRegion}
+	words = words[1:]
+	if len(words) > 0 && looksLikeRegion(words[0]) {
+		query.Region = strings.ToLower(words[0])
+		words = words[1:]
+	}
+	query.Quota = strings.Join(words, " ")
+	if !slashNamePattern.MatchString(query.Service) {
+		return slashQuery{}, fmt.Errorf("invalid service %q. %s", query.Service, slashUsage)
+	}
+	if !slashNamePattern.MatchString(query.Region) {
+		return slashQuery{}, fmt.Errorf("invalid region %q. %s", query.Region, slashUsage)
+	}
+	return query, nil
+}
+
+func (q slashQuery) matches(info QuotaInfo) bool {
+	if q.Quota == "" {
+		return true
+	}
+	return strings.EqualFold(info.QuotaCode, q.Quota) ||
+		strings.Contains(strings.ToLower(info.QuotaName), strings.ToLower(q.Quota))
+}
+
+// SlashCommandServer answers Slack slash commands with live quota lookups
+type SlashCommandServer struct {
+	Config        aws.Config
+	Cache         *QuotaCache
+	SigningSecret string
+	DefaultRegion string
+	Thresholds    Thresholds
+	Client        *http.Client
+}
+
+func (s *SlashCommandServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "error reading request", http.StatusBadRequest)
+		return
+	}
+	if err := verifySlackSignature(s.SigningSecret, r.Header, body, time.Now()); err != nil {
+		log.Printf("⚠️ Rejected slash command from %s: %v", r.RemoteAddr, err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseSlashQuery(form.Get("text"), s.DefaultRegion)
+	if err != nil {
+		s.reply(w, err.Error())
+		return
+	}
+	responseURL := form.Get("response_url")
+	if responseURL == "" {
+		http.Error(w, "missing response_url", http.StatusBadRequest)
+		return
+	}
+
+	// Slack expects an answer within 3 seconds, so acknowledge now and post the result later
+	log.Printf("🔎 Slash command from %s: %s %s %q", form.Get("user_name"), query.Service, query.Region, query.Quota)
+	go s.lookup(query, responseURL)
+	s.reply(w, fmt.Sprintf("Looking up `%s` quotas in `%s`…", query.Service, query.Region))
+}
+
+// reply sends an ephemeral message only the invoking user sees
+func (s *SlashCommandServer) reply(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(slackMessage{ResponseType: "ephemeral", Text: text})
+}
+
+func (s *SlashCommandServer) lookup(query slashQuery, responseURL string) {
+	ctx, cancel := context.WithTimeout(context.Background(), slashLookupTimeout)
+	defer cancel()
+
+	msg := s.lookupMessage(ctx, query)
+	webhook := &SlackWebhookNotifier{URL: responseURL, Client: s.Client}
+	if err := webhook.post(ctx, msg); err != nil {
+		log.Printf("❌ Error posting slash command result: %v", err)
+	}
+}
+
+func (s *SlashCommandServer) lookupMessage(ctx context.Context, query slashQuery) slackMessage {
+	cfg := s.Config.Copy()
+	cfg.Region = query.Region
+	quotas, err := FetchServiceQuotas(ctx, cfg, query.Service, query.Region, s.Cache)
+	if err != nil {
+		log.Printf("❌ Error fetching quotas for %s in %s: %v", query.Service, query.Region, err)
+		return slackMessage{ResponseType: "ephemeral", Text: fmt.Sprintf("❌ Could not fetch `%s` quotas in `%s`: %v", query.Service, query.Region, err)}
+	}
+
+	var matched []QuotaInfo
+	for _, q := range quotas {
+		if query.matches(q) {
+			matched = append(matched, q)
+		}
+	}
+	if len(matched) == 0 {
+		return slackMessage{ResponseType: "ephemeral", Text: fmt.Sprintf("No `%s` quotas matching %q in `%s`", query.Service, query.Quota, query.Region)}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].UtilizedPerc > matched[j].UtilizedPerc })
+
+	report := &Report{Regions: []string{query.Region}, GeneratedAt: time.Now(), Thresholds: s.Thresholds, Quotas: matched}
+	shown := matched
+	if len(shown) > slashMaxQuotas {
+		shown = shown[:slashMaxQuotas]
+	}
+	blocks := []slackBlock{slackHeader(fmt.Sprintf("%s quotas in %s", query.Service, query.Region))}
+	blocks = append(blocks, slackQuotaSections(report, shown)...)
+	if hidden := len(matched) - len(shown); hidden > 0 {
+		blocks = append(blocks, slackContext(fmt.Sprintf("…and %d more; add a quota code or name to narrow down", hidden)))
+	}
+	return slackMessage{
+		ResponseType: "ephemeral",
+		Text:         fmt.Sprintf("%d %s quotas in %s", len(matched), query.Service, query.Region),
+		Blocks:       blocks,
+	}
+}
+
+// runServe serves slash commands (when a signing secret is set) and health checks until the process is stopped
+func runServe(cfg aws.Config, addr string, secret string, thresholds Thresholds, cache *QuotaCache) {
+	mux := http.NewServeMux()
+	if secret != "" {
+		mux.Handle("/slack/commands", &SlashCommandServer{
+			Config:        cfg,
+			Cache:         cache,
+			SigningSecret: secret,
+			DefaultRegion: cfg.Region,
+			Thresholds:    thresholds,
+			Client:        newNotifyHTTPClient(),
+		})
+		log.Printf("🚀 Serving Slack slash commands on %s/slack/commands", addr)
+		fmt.Printf("Serving Slack slash commands on %s/slack/commands\n", addr)
+	}
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Fatalf("❌ Error: %v", server.ListenAndServe())
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signSlackRequest(secret string, ts time.Time, body string) http.Header {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := "command=%2Fquota&text=ec2"
+	tests := []struct {
+		name    string
+		header  http.Header
+		wantErr string
+	}{
+		{"valid", signSlackRequest("secret", now, body), ""},
+		{"bad signature", signSlackRequest("other-secret", now, body), "signature mismatch"},
+		{"stale timestamp", signSlackRequest("secret", now.Add(-10*time.Minute), body), "request timestamp is too old"},
+		{"future timestamp", signSlackRequest("secret", now.Add(10*time.Minute), body), "request timestamp is too old"},
+		{"missing timestamp", http.Header{}, "missing or invalid request timestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySlackSignature("secret", tt.header, []byte(body), now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSlashQuery(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    slashQuery
+		wantErr string
+	}{
+		{"ec2", slashQuery{Service: "ec2", Region: "us-east-1"}, ""},
+		{"EC2 us-west-2 L-1216C47A", slashQuery{Service: "ec2", Region: "us-west-2", Quota: "L-1216C47A"}, ""},
+		{"lambda concurrent executions", slashQuery{Service: "lambda", Region: "us-east-1", Quota: "concurrent executions"}, ""},
+		{"", slashQuery{}, "Usage:"},
+		{"help", slashQuery{}, "Usage:"},
+		{"../../etc us-east-1", slashQuery{}, `invalid service "../../etc"`},
+		{"ec2 us-east-1/../x-1", slashQuery{}, `invalid region "us-east-1/../x-1"`},
+	}
+	for _, tt := range tests {
+		got, err := parseSlashQuery(tt.text, "us-east-1")
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("parseSlashQuery(%q) error = %v, want prefix %q", tt.text, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSlashQuery(%q) = %+v, %v, want %+v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestSlashCommandServerRejectsBadRequests(t *testing.T) {
+	server := &SlashCommandServer{SigningSecret: "secret", DefaultRegion: "us-east-1"}
+	tests := []struct {
+		name       string
+		body       string
+		header     http.Header
+		wantStatus int
+		wantText   string
+	}{
+		{"unsigned", "text=ec2", http.Header{}, http.StatusUnauthorized, ""},
+		{"malformed form", "text=%zz", signSlackRequest("secret", time.Now(), "text=%zz"), http.StatusBadRequest, ""},
+		{"missing response_url", "text=ec2", signSlackRequest("secret", time.Now(), "text=ec2"), http.StatusBadRequest, ""},
+		{"invalid service", "text=..%2Fetc", signSlackRequest("secret", time.Now(), "text=..%2Fetc"), http.StatusOK, "invalid service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantText != "" && !strings.Contains(rec.Body.String(), tt.wantText) {
+				t.Errorf("reply %q lacks %q", rec.Body.String(), tt.wantText)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// teamsMaxPayload is the largest message Teams webhooks accept
+const teamsMaxPayload = 28 * 1024
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string                   `json:"$schema"`
+	Type    string                   `json:"type"`
+	Version string                   `json:"version"`
+	Body    []map[string]interface{} `json:"body"`
+	MSTeams map[string]string        `json:"msteams,omitempty"`
+}
+
+// teamsColor maps severities to Adaptive Card text colours
+func teamsColor(s Severity) string {
+	switch s {
+	case SeverityCritical:
+		return "Attention"
+	case SeverityWarning:
+		return "Warning"
+	default:
+		return "Good"
+	}
+}
+
+func teamsText(text string, extra map[string]interface{}) map[string]interface{} {
+	block := map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true}
+	for k, v := range extra {
+		block[k] = v
+	}
+	return block
+}
+
+func teamsFacts(facts [][2]string) map[string]interface{} {
+	list := make([]map[string]string, 0, len(facts))
+	for _, f := range facts {
+		list = append(list, map[string]string{"title": f[0], "value": f[1]})
+	}
+	return map[string]interface{}{"type": "FactSet", "facts": list}
+}
+
+// buildTeamsCard renders a report as an Adaptive Card listing at most maxQuotas breaching quotas
+// and maxEvents alert changes (0 lists all)
+func buildTeamsCard(report *Report, maxQuotas int, maxEvents int) teamsMessage {
+	counts := report.SeverityCounts()
+	summary := make([]map[string]interface{}, 0, 3)
+	for _, s := range []Severity{SeverityCritical, SeverityWarning, SeverityOK} {
+		summary = append(summary, map[string]interface{}{
+			"type":  "Column",
+			"width": "stretch",
+			"items": []map[string]interface{}{
+				teamsText(strings.ToUpper(s.String()), map[string]interface{}{"isSubtle": true, "size": "Small"}),
+				teamsText(fmt.Sprint(counts[s]), map[string]interface{}{"color": teamsColor(s), "size": "ExtraLarge", "weight": "Bolder"}),
+			},
+		})
+	}
+
+	body := []map[string]interface{}{
+		teamsText("AWS Service Quota Report", map[string]interface{}{"size": "Large", "weight": "Bolder"}),
+		teamsFacts([][2]string{
+			{"Account", report.AccountID},
+			{"Regions", strings.Join(report.Regions, ", ")},
+			{"Run", report.GeneratedAt.UTC().Format(time.RFC1123)},
+			{"Thresholds", fmt.Sprintf("warning ≥ %.0f%%, critical ≥ %.0f%%", report.Thresholds.Warning, report.Thresholds.Critical)},
+		}),
+		{"type": "ColumnSet", "columns": summary, "separator": true},
+	}
+
+	if len(report.Events) > 0 {
+		events := report.Events
+		if maxEvents > 0 && len(events) > maxEvents {
+			events = events[:maxEvents]
+		}
+		var facts [][2]string
+		for _, e := range events {
+			q := e.Quota
+			facts = append(facts, [2]string{
+				fmt.Sprintf("%s: %s %s (%s)", e.Kind.Label(), q.ServiceName, q.QuotaCode, q.Region),
+				fmt.Sprintf("%s — %s → %s (%.2f%%)", q.QuotaName, e.Previous, e.Severity, q.UtilizedPerc),
+			})
+		}
+		body = append(body,
+			teamsText(fmt.Sprintf("Alert changes (%d)", len(report.Events)), map[string]interface{}{"weight": "Bolder", "separator": true}),
+			teamsFacts(facts),
+		)
+		if hidden := len(report.Events) - len(events); hidden > 0 {
+			body = append(body, teamsText(fmt.Sprintf("…and %d more alert changes", hidden), map[string]interface{}{"isSubtle": true}))
+		}
+	}
+
+	breaching := report.Breaching()
+	if len(breaching) == 0 {
+		body = append(body, teamsText(fmt.Sprintf("✅ No quotas at or above %.0f%% utilization", report.Thresholds.Warning), map[string]interface{}{"color": "Good"}))
+	}
+	shown := breaching
+	if maxQuotas > 0 && len(shown) > maxQuotas {
+		shown = shown[:maxQuotas]
+	}
+	for _, s := range []Severity{SeverityCritical, SeverityWarning} {
+		title := map[Severity]string{SeverityCritical: "Critical", SeverityWarning: "Warning"}[s]
+		var facts [][2]string
+		for _, q := range shown {
+			if report.Severity(q) == s {
+				facts = append(facts, [2]string{
+					fmt.Sprintf("%s %s (%s)", q.ServiceName, q.QuotaCode, q.Region),
+					fmt.Sprintf("%s — %.0f / %.0f (%.2f%%)", q.QuotaName, q.Used, q.Allocated, q.UtilizedPerc),
+				})
+			}
+		}
+		if len(facts) == 0 {
+			continue
+		}
+		body = append(body,
+			teamsText(fmt.Sprintf("%s (%d)", title, len(facts)), map[string]interface{}{"color": teamsColor(s), "weight": "Bolder", "separator": true}),
+			teamsFacts(facts),
+		)
+	}
+	if hidden := len(breaching) - len(shown); hidden > 0 {
+		body = append(body, teamsText(fmt.Sprintf("…and %d more breaching quotas", hidden), map[string]interface{}{"isSubtle": true}))
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+				MSTeams: map[string]string{"width": "Full"},
+			},
+		}},
+	}
+}
+
+// TeamsNotifier posts Adaptive Card reports to a Teams Workflows or incoming webhook
+type TeamsNotifier struct {
+	URL       string
+	MaxQuotas int
+	Client    *http.Client
+}
+
+func (n *TeamsNotifier) Name() string {
+	return "Teams webhook"
+}
+
+func (n *TeamsNotifier) Notify(ctx context.Context, report *Report) error {
+	payload, err := n.payload(report)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// Workflows answer 202 Accepted, legacy connectors 200 OK
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("teams webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (n *TeamsNotifier) Preview(report *Report) ([]byte, error) {
+	return n.payload(report)
+}
+
+// payload encodes the card, halving the quota list and then the alert changes until it
+// fits the Teams size limit
+func (n *TeamsNotifier) payload(report *Report) ([]byte, error) {
+	maxQuotas := n.MaxQuotas
+	if maxQuotas <= 0 {
+		maxQuotas = len(report.Quotas)
+	}
+	maxEvents := len(report.Events)
+	for {
+		payload, err := json.Marshal(buildTeamsCard(report, maxQuotas, maxEvents))
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling Teams card: %v", err)
+		}
+		switch {
+		case len(payload) <= teamsMaxPayload:
+			return payload, nil
+		case maxQuotas > 1:
+			maxQuotas /= 2
+		case maxEvents > 1:
+			maxEvents /= 2
+		default:
+			return nil, fmt.Errorf("teams card is %d bytes even with one quota and one alert change, over the %d byte limit", len(payload), teamsMaxPayload)
+		}
+	}
+}
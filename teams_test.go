@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata/")
+
+// checkGolden compares got with testdata/name, or rewrites the file with -update
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run go test -update to create it): %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("output differs from %s (run go test -update to accept it)\ngot:\n%s", path, got)
+	}
+}
+
+func TestBuildTeamsCardGolden(t *testing.T) {
+	critical := QuotaInfo{ServiceName: "Amazon EC2", QuotaCode: "L-1216C47A", QuotaName: "Running On-Demand Standard instances", Region: "us-east-1", Allocated: 100, Used: 95, UtilizedPerc: 95}
+	report := &Report{
+		AccountID:   "123456789012",
+		Regions:     []string{"us-east-1", "eu-west-1"},
+		GeneratedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Thresholds:  Thresholds{Warning: 80, Critical: 90},
+		Quotas: []QuotaInfo{
+			critical,
+			{ServiceName: "Amazon VPC", QuotaCode: "L-F678F1CE", QuotaName: "VPCs per Region", Region: "eu-west-1", Allocated: 5, Used: 4, UtilizedPerc: 80},
+			{ServiceName: "AWS Lambda", QuotaCode: "L-B99A9384", QuotaName: "Concurrent executions", Region: "us-east-1", Allocated: 1000, Used: 120, UtilizedPerc: 12},
+		},
+		Events: []AlertEvent{{Kind: AlertNew, Quota: critical, Severity: SeverityCritical, Previous: SeverityOK}},
+	}
+
+	got, err := json.MarshalIndent(buildTeamsCard(report, 25, 0), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "teams_card.json", append(got, '\n'))
+}
+
+func TestTeamsPayloadTrimsEvents(t *testing.T) {
+	report := newTestReport(2000)
+	for _, q := range report.Quotas {
+		report.Events = append(report.Events, AlertEvent{Kind: AlertNew, Quota: q, Severity: report.Severity(q), Previous: SeverityOK})
+	}
+	n := &TeamsNotifier{MaxQuotas: 25}
+	payload, err := n.payload(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(payload) > teamsMaxPayload {
+		t.Fatalf("payload is %d bytes, want at most %d", len(payload), teamsMaxPayload)
+	}
+	if !json.Valid(payload) {
+		t.Fatal("payload is not valid JSON")
+	}
+	for _, want := range []string{"Alert changes (2000)", "more alert changes", "more breaching quotas"} {
+		if !strings.Contains(string(payload), want) {
+			t.Errorf("payload is missing %q", want)
+		}
+	}
+}
+
+func TestTeamsPayloadTooLarge(t *testing.T) {
+	report := newTestReport(1)
+	report.Quotas[0].QuotaName = strings.Repeat("x", teamsMaxPayload)
+	report.Events = []AlertEvent{{Kind: AlertNew, Quota: report.Quotas[0], Severity: SeverityWarning}}
+	if _, err := (&TeamsNotifier{}).payload(report); err == nil || !strings.Contains(err.Error(), "byte limit") {
+		t.Errorf("payload error = %v, want the size limit reported", err)
+	}
+}
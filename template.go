@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// templateSummary counts quotas per severity for the whole report or one group
+type templateSummary struct {
+	Total          int
+	Critical       int
+	Warning        int
+	OK             int
+	MaxUtilization float64
+}
+
+func summarize(quotas []quotaRecord) templateSummary {
+	s := templateSummary{Total: len(quotas)}
+	for _, q := range quotas {
+		switch q.Severity {
+		case SeverityCritical.String():
+			s.Critical++
+		case SeverityWarning.String():
+			s.Warning++
+		default:
+			s.OK++
+		}
+		s.MaxUtilization = max(s.MaxUtilization, q.UtilizedPerc)
+	}
+	return s
+}
+
+// templateGroup is one group returned by the groupBy helper
+type templateGroup struct {
+	Key     string
+	Quotas  []quotaRecord
+	Summary templateSummary
+}
+
+// templateData is what user templates render; quotas use the same records as JSON reports
+type templateData struct {
+	AccountID   string
+	Regions     []string
+	GeneratedAt time.Time
+	Thresholds  Thresholds
+	Version     string
+	Quotas      []quotaRecord
+	Errors      []FetchError
+	Summary     templateSummary
+}
+
+func newTemplateData(report *Report) templateData {
+	data := templateData{
+		AccountID:   report.AccountID,
+		Regions:     report.Regions,
+		GeneratedAt: report.GeneratedAt,
+		Thresholds:  report.Thresholds,
+		Version:     version,
+		Errors:      report.Errors,
+	}
+	for _, q := range report.Quotas {
+		data.Quotas = append(data.Quotas, newQuotaRecord(report, q))
+	}
+	data.Summary = summarize(data.Quotas)
+	return data
+}
+
+// templateField returns the value of a quota field by the names the helpers accept
+func templateField(q quotaRecord, field string) (interface{}, error) {
+	switch field {
+	case "account":
+		return q.Account, nil
+	case "service":
+		return q.Service, nil
+	case "region":
+		return q.Region, nil
+	case "code":
+		return q.QuotaCode, nil
+	case "name":
+		return q.QuotaName, nil
+	case "severity":
+		return q.Severity, nil
+	case "allocated":
+		return q.Allocated, nil
+	case "used":
+		return q.Used, nil
+	case "utilization":
+		return q.UtilizedPerc, nil
+	case "headroom":
+		return q.Allocated - q.Used, nil
+	default:
+		return nil, fmt.Errorf("unknown field %q (use account, service, region, code, name, severity, allocated, used, utilization or headroom)", field)
+	}
+}
+
+// sortQuotas returns a sorted copy: numbers descending, except headroom (least first), and text ascending
+func sortQuotas(field string, quotas []quotaRecord) ([]quotaRecord, error) {
+	if _, err := templateField(quotaRecord{}, field); err != nil {
+		return nil, err
+	}
+	sorted := append([]quotaRecord(nil), quotas...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := templateField(sorted[i], field)
+		b, _ := templateField(sorted[j], field)
+		switch a := a.(type) {
+		case float64:
+			if field == "headroom" {
+				return a < b.(float64)
+			}
+			return a > b.(float64)
+		default:
+			return a.(string) < b.(string)
+		}
+	})
+	return sorted, nil
+}
+
+// groupQuotas groups by a text field, in order of the group keys
+func groupQuotas(field string, quotas []quotaRecord) ([]templateGroup, error) {
+	// Validate up front so a bad field fails even when there is nothing to group
+	value, err := templateField(quotaRecord{}, field)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(string); !ok {
+		return nil, fmt.Errorf("cannot group by numeric field %q", field)
+	}
+	index := map[string]int{}
+	var groups []templateGroup
+	for _, q := range quotas {
+		value, _ := templateField(q, field)
+		key := value.(string)
+		if _, seen := index[key]; !seen {
+			index[key] = len(groups)
+			groups = append(groups, templateGroup{Key: key})
+		}
+		groups[index[key]].Quotas = append(groups[index[key]].Quotas, q)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	for i := range groups {
+		groups[i].Summary = summarize(groups[i].Quotas)
+	}
+	return groups, nil
+}
+
+// atLeastSeverity keeps quotas at or above a severity (ok, warning or critical)
+func atLeastSeverity(severity string, quotas []quotaRecord) ([]quotaRecord, error) {
+	var threshold Severity
+	if err := threshold.UnmarshalText([]byte(severity)); err != nil {
+		return nil, err
+	}
+	var kept []quotaRecord
+	for _, q := range quotas {
+		var s Severity
+		s.UnmarshalText([]byte(q.Severity))
+		if s >= threshold {
+			kept = append(kept, q)
+		}
+	}
+	return kept, nil
+}
+
+// templateFuncs are the helpers available to --template files
+var templateFuncs = map[string]interface{}{
+	"percent": formatPercent,
+	"number":  func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) },
+	"sortBy":  sortQuotas,
+	"groupBy": groupQuotas,
+	"atLeast": atLeastSeverity,
+	"top": func(n int, quotas []quotaRecord) []quotaRecord {
+		return quotas[:min(max(n, 0), len(quotas))]
+	},
+	"summarize": summarize,
+	"join":      strings.Join,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"date":      func(layout string, t time.Time) string { return t.UTC().Format(layout) },
+}
+
+// reportTemplate is satisfied by both text/template and html/template
+type reportTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// loadTemplate parses a template file; .html and .htm files (also as .html.tmpl) get html/template's escaping
+func loadTemplate(path string) (reportTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(path)
+	if ext := filepath.Ext(strings.TrimSuffix(name, ".tmpl")); ext == ".html" || ext == ".htm" {
+		return htmltemplate.New(name).Funcs(templateFuncs).Parse(string(text))
+	}
+	return texttemplate.New(name).Funcs(templateFuncs).Parse(string(text))
+}
+
+// RenderTemplate renders the report through a template file to outputPath, or stdout when empty
+func RenderTemplate(report *Report, templatePath string, outputPath string) error {
+	tmpl, err := loadTemplate(templatePath)
+	if err != nil {
+		return err
+	}
+	data := newTemplateData(report)
+	if outputPath == "" {
+		return tmpl.Execute(os.Stdout, data)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
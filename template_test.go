@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateHelpersRejectUnknownFields(t *testing.T) {
+	quotas := newTemplateData(newTestReport(3)).Quotas
+	for _, q := range [][]quotaRecord{quotas, nil} {
+		if _, err := sortQuotas("owner", q); err == nil || !strings.Contains(err.Error(), `unknown field "owner"`) {
+			t.Errorf("sortBy owner with %d quotas: err = %v", len(q), err)
+		}
+		if _, err := groupQuotas("owner", q); err == nil || !strings.Contains(err.Error(), `unknown field "owner"`) {
+			t.Errorf("groupBy owner with %d quotas: err = %v", len(q), err)
+		}
+		for _, field := range []string{"allocated", "used", "utilization", "headroom"} {
+			if _, err := groupQuotas(field, q); err == nil || !strings.Contains(err.Error(), "cannot group by numeric field") {
+				t.Errorf("groupBy %s with %d quotas: err = %v", field, len(q), err)
+			}
+		}
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	quotas := newTemplateData(newTestReport(6)).Quotas
+
+	sorted, err := sortQuotas("utilization", quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].UtilizedPerc < sorted[i].UtilizedPerc {
+			t.Fatalf("sortBy utilization is not descending: %v then %v", sorted[i-1].UtilizedPerc, sorted[i].UtilizedPerc)
+		}
+	}
+
+	groups, err := groupQuotas("service", quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 || groups[0].Key != "Service 0" || groups[0].Summary.Total != 2 {
+		t.Errorf("groupBy service = %+v", groups)
+	}
+
+	if _, err := atLeastSeverity("urgent", quotas); err == nil {
+		t.Error("atLeast accepted an unknown severity")
+	}
+}
+
+func TestRenderTemplateSurfacesHelperErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range groupBy "used" .Quotas}}{{.Key}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := RenderTemplate(newTestReport(3), path, filepath.Join(dir, "out.txt"))
+	if err == nil || !strings.Contains(err.Error(), `cannot group by numeric field "used"`) {
+		t.Errorf("RenderTemplate err = %v", err)
+	}
+
+	good := filepath.Join(dir, "good.tmpl")
+	if err := os.WriteFile(good, []byte(`{{range groupBy "service" .Quotas}}{{.Key}}={{.Summary.Total}} {{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "good.txt")
+	if err := RenderTemplate(newTestReport(3), good, out); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "Service 0=1 Service 1=1 Service 2=1 " {
+		t.Errorf("rendered %q", got)
+	}
+}
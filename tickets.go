@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	githubDefaultAPIURL = "https://api.github.com"
+	ticketLabel         = "aws-service-quota"
+)
+
+// suggestedQuotaValue is a limit that would bring the quota to half the warning threshold
+func suggestedQuotaValue(q QuotaInfo, t Thresholds) float64 {
+	target := t.Warning / 2 / 100
+	if target <= 0 {
+		target = 0.5
+	}
+	return math.Max(math.Ceil(q.Used/target), math.Ceil(q.Allocated*1.2))
+}
+
+func ticketTitle(q QuotaInfo) string {
+	return fmt.Sprintf("AWS quota %s (%s) in %s above threshold", q.QuotaName, q.QuotaCode, q.Region)
+}
+
+func ticketBody(report *Report, e AlertEvent) string {
+	q := e.Quota
+	var b strings.Builder
+	fmt.Fprintf(&b, "The %s quota %q has been above the warning threshold for %d consecutive runs.\n\n", q.ServiceName, q.QuotaName, e.Runs)
+	fmt.Fprintf(&b, "- Account: %s\n", report.AccountID)
+	fmt.Fprintf(&b, "- Region: %s\n", q.Region)
+	fmt.Fprintf(&b, "- Service: %s\n", q.ServiceName)
+	fmt.Fprintf(&b, "- Quota code: %s\n", q.QuotaCode)
+	fmt.Fprintf(&b, "- Usage: %.0f / %.0f (%.2f%%, %s)\n", q.Used, q.Allocated, q.UtilizedPerc, e.Severity)
+	fmt.Fprintf(&b, "- Adjustable: %t\n", q.Adjustable)
+	if q.Adjustable {
+		fmt.Fprintf(&b, "- Suggested new value: %.0f\n\n", suggestedQuotaValue(q, report.Thresholds))
+		fmt.Fprintf(&b, "Request an increase with:\n\n    aws service-quotas request-service-quota-increase --region %s --service-code %s --quota-code %s --desired-value %.0f\n",
+			q.Region, q.ServiceName, q.QuotaCode, suggestedQuotaValue(q, report.Thresholds))
+	}
+	fmt.Fprintf(&b, "\nKey: %s\n", e.Key)
+	return b.String()
+}
+
+// ticketAPI opens, updates and closes one ticket per sustained breach
+type ticketAPI interface {
+	Create(ctx context.Context, title string, body string) (string, error)
+	Comment(ctx context.Context, id string, body string) error
+	Close(ctx context.Context, id string, body string) error
+}
+
+// TicketNotifier opens a ticket once a quota has breached for MinRuns consecutive runs,
+// comments when its severity changes and closes it on recovery. Ticket IDs live in the alert
+// state; a breach without one gets a ticket on every run until it is opened.
+type TicketNotifier struct {
+	Label   string
+	API     ticketAPI
+	MinRuns int
+	State   *AlertState
+}
+
+func (n *TicketNotifier) Name() string {
+	return n.Label
+}
+
+func (n *TicketNotifier) Notify(ctx context.Context, report *Report) error {
+	var errs []error
+	for _, e := range report.Events {
+		id := e.Tickets[n.Label]
+		q := e.Quota
+		var err error
+		switch {
+		case id == "":
+			continue
+		case e.Kind == AlertRecovered:
+			err = n.API.Close(ctx, id, fmt.Sprintf("Recovered: utilization dropped to %.2f%% (%.0f / %.0f).", q.UtilizedPerc, q.Used, q.Allocated))
+		case e.Kind == AlertEscalated || e.Kind == AlertDeescalated:
+			err = n.API.Comment(ctx, id, fmt.Sprintf("Severity changed from %s to %s: utilization is %.2f%% (%.0f / %.0f).",
+				e.Previous, e.Severity, q.UtilizedPerc, q.Used, q.Allocated))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", e.Key, err))
+		}
+	}
+
+	// Open tickets from the state rather than the events, so a failed Create is retried next run
+	for _, q := range report.Quotas {
+		key := quotaKey(report, q)
+		record, ok := n.State.Alerts[key]
+		severity := report.Severity(q)
+		if !ok || severity == SeverityOK || record.Runs < n.MinRuns || record.Tickets[n.Label] != "" {
+			continue
+		}
+		e := AlertEvent{Kind: AlertSustained, Key: key, Quota: q, Severity: severity, Runs: record.Runs}
+		id, err := n.API.Create(ctx, ticketTitle(q), ticketBody(report, e))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", key, err))
+			continue
+		}
+		n.State.SetTicket(key, n.Label, id)
+	}
+	return errors.Join(errs...)
+}
+
+// ticketRequest sends an optional JSON body and decodes an optional JSON answer
+func ticketRequest(ctx context.Context, client *http.Client, method string, target string, headers map[string]string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshaling payload: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", method, target, resp.Status, truncate(strings.TrimSpace(string(respBody)), 500))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("error decoding response: %v", err)
+		}
+	}
+	return nil
+}
+
+// GitHubIssues files tickets as issues in one repository
+type GitHubIssues struct {
+	Repo   string // owner/name
+	Token  string
+	APIURL string
+	Client *http.Client
+}
+
+func (g *GitHubIssues) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	headers := map[string]string{
+		"Authorization":        "Bearer " + g.Token,
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}
+	target := strings.TrimSuffix(g.APIURL, "/") + "/repos/" + g.Repo + path
+	return ticketRequest(ctx, g.Client, method, target, headers, body, out)
+}
+
+func (g *GitHubIssues) Create(ctx context.Context, title string, body string) (string, error) {
+	var issue struct {
+		Number int `json:"number"`
+	}
+	err := g.do(ctx, http.MethodPost, "/issues", map[string]interface{}{
+		"title":  title,
+		"body":   body,
+		"labels": []string{ticketLabel},
+	}, &issue)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(issue.Number), nil
+}
+
+func (g *GitHubIssues) Comment(ctx context.Context, id string, body string) error {
+	return g.do(ctx, http.MethodPost, "/issues/"+id+"/comments", map[string]string{"body": body}, nil)
+}
+
+func (g *GitHubIssues) Close(ctx context.Context, id string, body string) error {
+	if err := g.Comment(ctx, id, body); err != nil {
+		return err
+	}
+	return g.do(ctx, http.MethodPatch, "/issues/"+id, map[string]string{"state": "closed", "state_reason": "completed"}, nil)
+}
+
+// JiraIssues files tickets in a Jira project through the REST API v2
+type JiraIssues struct {
+	URL            string
+	User           string
+	Token          string
+	Project        string
+	IssueType      string
+	DoneTransition string // workflow transition used to close, e.g. "Done"
+	Client         *http.Client
+}
+
+func (j *JiraIssues) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	headers := map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(j.User+":"+j.Token))}
+	target := strings.TrimSuffix(j.URL, "/") + "/rest/api/2" + path
+	return ticketRequest(ctx, j.Client, method, target, headers, body, out)
+}
+
+func (j *JiraIssues) Create(ctx context.Context, title string, body string) (string, error) {
+	var issue struct {
+		Key string `json:"key"`
+	}
+	err := j.do(ctx, http.MethodPost, "/issue", map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": j.Project},
+			"issuetype":   map[string]string{"name": j.IssueType},
+			"summary":     truncate(title, 255),
+			"description": body,
+			"labels":      []string{ticketLabel},
+		},
+	}, &issue)
+	if err != nil {
+		return "", err
+	}
+	return issue.Key, nil
+}
+
+func (j *JiraIssues) Comment(ctx context.Context, id string, body string) error {
+	return j.do(ctx, http.MethodPost, "/issue/"+url.PathEscape(id)+"/comment", map[string]string{"body": body}, nil)
+}
+
+func (j *JiraIssues) Close(ctx context.Context, id string, body string) error {
+	if err := j.Comment(ctx, id, body); err != nil {
+		return err
+	}
+	var transitions struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := j.do(ctx, http.MethodGet, "/issue/"+url.PathEscape(id)+"/transitions", nil, &transitions); err != nil {
+		return err
+	}
+	for _, t := range transitions.Transitions {
+		if strings.EqualFold(t.Name, j.DoneTransition) {
+			return j.do(ctx, http.MethodPost, "/issue/"+url.PathEscape(id)+"/transitions",
+				map[string]interface{}{"transition": map[string]string{"id": t.ID}}, nil)
+		}
+	}
+	return fmt.Errorf("issue %s has no %q transition", id, j.DoneTransition)
+}
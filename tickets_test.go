@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ticketCall is one request a fake tracker received
+type ticketCall struct {
+	Method, Path, Auth string
+	Body               map[string]interface{}
+}
+
+func newTicketServer(t *testing.T, handle func(w http.ResponseWriter, call ticketCall)) (*httptest.Server, *[]ticketCall) {
+	t.Helper()
+	var calls []ticketCall
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := ticketCall{Method: r.Method, Path: r.URL.Path, Auth: r.Header.Get("Authorization")}
+		json.NewDecoder(r.Body).Decode(&call.Body)
+		calls = append(calls, call)
+		handle(w, call)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestGitHubIssuesLifecycle(t *testing.T) {
+	server, calls := newTicketServer(t, func(w http.ResponseWriter, call ticketCall) {
+		if call.Method == http.MethodPost && call.Path == "/repos/example/capacity/issues" {
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number": 42}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+	g := &GitHubIssues{Repo: "example/capacity", Token: "ghp_test", APIURL: server.URL + "/", Client: server.Client()}
+
+	id, err := g.Create(context.Background(), "title", "body")
+	if err != nil || id != "42" {
+		t.Fatalf("Create = %q, %v", id, err)
+	}
+	if err := g.Close(context.Background(), id, "recovered"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := []string{"POST /repos/example/capacity/issues", "POST /repos/example/capacity/issues/42/comments", "PATCH /repos/example/capacity/issues/42"}
+	if len(*calls) != len(want) {
+		t.Fatalf("got %d calls, want %d", len(*calls), len(want))
+	}
+	for i, call := range *calls {
+		if got := call.Method + " " + call.Path; got != want[i] {
+			t.Errorf("call %d = %s, want %s", i, got, want[i])
+		}
+		if call.Auth != "Bearer ghp_test" {
+			t.Errorf("call %d Authorization = %q", i, call.Auth)
+		}
+	}
+	if labels := (*calls)[0].Body["labels"]; fmt.Sprint(labels) != "["+ticketLabel+"]" {
+		t.Errorf("labels = %v", labels)
+	}
+	if state := (*calls)[2].Body["state"]; state != "closed" {
+		t.Errorf("state = %v", state)
+	}
+}
+
+func TestJiraIssuesLifecycle(t *testing.T) {
+	server, calls := newTicketServer(t, func(w http.ResponseWriter, call ticketCall) {
+		switch {
+		case call.Method == http.MethodPost && call.Path == "/rest/api/2/issue":
+			fmt.Fprint(w, `{"key": "OPS-7"}`)
+		case call.Method == http.MethodGet && strings.HasSuffix(call.Path, "/transitions"):
+			fmt.Fprint(w, `{"transitions": [{"id": "11", "name": "In Progress"}, {"id": "31", "name": "Done"}]}`)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	j := &JiraIssues{URL: server.URL, User: "bot@example.com", Token: "token", Project: "OPS", IssueType: "Task", DoneTransition: "done", Client: server.Client()}
+
+	id, err := j.Create(context.Background(), "title", "body")
+	if err != nil || id != "OPS-7" {
+		t.Fatalf("Create = %q, %v", id, err)
+	}
+	if err := j.Close(context.Background(), id, "recovered"); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := []string{"POST /rest/api/2/issue", "POST /rest/api/2/issue/OPS-7/comment", "GET /rest/api/2/issue/OPS-7/transitions", "POST /rest/api/2/issue/OPS-7/transitions"}
+	if len(*calls) != len(want) {
+		t.Fatalf("got %d calls, want %d", len(*calls), len(want))
+	}
+	for i, call := range *calls {
+		if got := call.Method + " " + call.Path; got != want[i] {
+			t.Errorf("call %d = %s, want %s", i, got, want[i])
+		}
+		if call.Auth != "Basic Ym90QGV4YW1wbGUuY29tOnRva2Vu" {
+			t.Errorf("call %d Authorization = %q", i, call.Auth)
+		}
+	}
+	fields := (*calls)[0].Body["fields"].(map[string]interface{})
+	if fields["project"].(map[string]interface{})["key"] != "OPS" || fields["issuetype"].(map[string]interface{})["name"] != "Task" {
+		t.Errorf("fields = %v", fields)
+	}
+	if transition := (*calls)[3].Body["transition"].(map[string]interface{}); transition["id"] != "31" {
+		t.Errorf("transition = %v, want id 31", transition)
+	}
+}
+
+func TestJiraIssuesErrors(t *testing.T) {
+	server, _ := newTicketServer(t, func(w http.ResponseWriter, call ticketCall) {
+		if call.Method == http.MethodGet {
+			fmt.Fprint(w, `{"transitions": [{"id": "11", "name": "In Progress"}]}`)
+			return
+		}
+		if call.Path == "/rest/api/2/issue" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors": {"project": "project is required"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	j := &JiraIssues{URL: server.URL, Project: "OPS", IssueType: "Task", DoneTransition: "Done", Client: server.Client()}
+
+	if _, err := j.Create(context.Background(), "title", "body"); err == nil || !strings.Contains(err.Error(), "400 Bad Request") {
+		t.Errorf("Create error = %v, want the 400 answer", err)
+	}
+	if err := j.Close(context.Background(), "OPS-7", "recovered"); err == nil || err.Error() != `issue OPS-7 has no "Done" transition` {
+		t.Errorf("Close error = %v", err)
+	}
+}
+
+func TestTicketNotifierKeepsTicketIDsWhenOthersFail(t *testing.T) {
+	server, _ := newTicketServer(t, func(w http.ResponseWriter, call ticketCall) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 7}`)
+	})
+	path := filepath.Join(t.TempDir(), "state.json")
+	state, err := LoadAlertState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 10, Used: 10, UtilizedPerc: 100}
+	report := &Report{AccountID: "123456789012", GeneratedAt: time.Now(), Thresholds: Thresholds{Warning: 80, Critical: 90}, Quotas: []QuotaInfo{q}}
+	report.Events = state.Evaluate(report, report.Quotas, 0, 1)
+
+	tickets := &TicketNotifier{Label: "GitHub", API: &GitHubIssues{Repo: "example/capacity", APIURL: server.URL, Client: server.Client()}, MinRuns: 1, State: state}
+	failing := &SlackWebhookNotifier{URL: server.URL + "/broken", Client: &http.Client{Transport: failingTransport{}}}
+	failed := notifyAll(context.Background(), []notifyRoute{{Report: report, Notifiers: []Notifier{failing, tickets}}})
+	if failed != 1 {
+		t.Fatalf("failed = %d, want 1", failed)
+	}
+	if err := state.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadAlertState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := loaded.Alerts[report.Events[0].Key].Tickets["GitHub"]; id != "7" {
+		t.Errorf("saved ticket = %q, want 7", id)
+	}
+}
+
+func TestTicketNotifierRetriesFailedCreate(t *testing.T) {
+	creates := 0
+	server, calls := newTicketServer(t, func(w http.ResponseWriter, call ticketCall) {
+		creates++
+		if creates == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number": 9}`)
+	})
+	state, err := LoadAlertState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets := &TicketNotifier{Label: "GitHub", API: &GitHubIssues{Repo: "example/capacity", APIURL: server.URL, Client: server.Client()}, MinRuns: 1, State: state}
+	q := QuotaInfo{ServiceName: "ec2", QuotaCode: "L-1216C47A", Region: "us-east-1", Allocated: 10, Used: 10, UtilizedPerc: 100}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// renotify is 0, so runs after the first have no alert events
+	for run, wantFailed := range []int{1, 0, 0} {
+		report := &Report{AccountID: "123456789012", GeneratedAt: start.Add(time.Duration(run) * time.Hour), Thresholds: Thresholds{Warning: 80, Critical: 90}, Quotas: []QuotaInfo{q}}
+		report.Events = append([]AlertEvent{}, state.Evaluate(report, report.Quotas, 0, 1)...)
+		if run > 0 && len(report.Events) != 0 {
+			t.Fatalf("run %d: unexpected events %+v", run, report.Events)
+		}
+		failed := notifyAll(context.Background(), []notifyRoute{{Report: report, Notifiers: []Notifier{tickets}, EveryRun: true}})
+		if failed != wantFailed {
+			t.Fatalf("run %d: failed = %d, want %d", run, failed, wantFailed)
+		}
+	}
+	if len(*calls) != 2 {
+		t.Errorf("got %d create calls, want a failed one and one retry", len(*calls))
+	}
+	if id := state.Alerts[quotaKey(&Report{AccountID: "123456789012"}, q)].Tickets["GitHub"]; id != "9" {
+		t.Errorf("stored ticket = %q, want 9", id)
+	}
+}
+
+// failingTransport fails every request
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("connection refused")
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// webhookSignatureHeader carries the hex HMAC-SHA256 of the body when a secret is set
+const webhookSignatureHeader = "X-Signature-256"
+
+// WebhookNotifier sends a report as a request defined by a text/template file.
+// The file defines "body" and optionally "method", "url" and "headers" (one "Name: value" per line).
+type WebhookNotifier struct {
+	Template   *template.Template
+	URL        string // used when the template defines no "url"
+	HMACSecret string
+	Retries    int
+	Backoff    time.Duration
+	Client     *http.Client
+}
+
+type webhookRequest struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	Body    []byte
+}
+
+// webhookFuncs are the helpers available to webhook templates; severity helpers use the report's thresholds
+func webhookFuncs(report *Report) template.FuncMap {
+	parseSeverity := func(name string) (Severity, error) {
+		for _, s := range []Severity{SeverityOK, SeverityWarning, SeverityCritical} {
+			if s.String() == name {
+				return s, nil
+			}
+		}
+		return SeverityOK, fmt.Errorf("unknown severity %q", name)
+	}
+	return template.FuncMap{
+		"severity": func(q QuotaInfo) string {
+			return report.Severity(q).String()
+		},
+		"bySeverity": func(name string, quotas []QuotaInfo) ([]QuotaInfo, error) {
+			s, err := parseSeverity(name)
+			if err != nil {
+				return nil, err
+			}
+			var out []QuotaInfo
+			for _, q := range quotas {
+				if report.Severity(q) == s {
+					out = append(out, q)
+				}
+			}
+			return out, nil
+		},
+		"atLeast": func(name string, quotas []QuotaInfo) ([]QuotaInfo, error) {
+			s, err := parseSeverity(name)
+			if err != nil {
+				return nil, err
+			}
+			var out []QuotaInfo
+			for _, q := range quotas {
+				if report.Severity(q) >= s {
+					out = append(out, q)
+				}
+			}
+			return out, nil
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"percent": func(v float64) string {
+			return fmt.Sprintf("%.2f%%", v)
+		},
+		"rfc3339": func(t time.Time) string {
+			return t.UTC().Format(time.RFC3339)
+		},
+		"env":   webhookEnv,
+		"join":  strings.Join,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}
+}
+
+// webhookEnvPrefix limits what templates can read from the environment, so a template
+// cannot pull AWS credentials or other tokens into a request
+const webhookEnvPrefix = "WEBHOOK_"
+
+// webhookEnv reads WEBHOOK_* variables, except the HMAC secret
+func webhookEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, webhookEnvPrefix) || name == "WEBHOOK_HMAC_SECRET" {
+		return "", fmt.Errorf("env %q is not allowed: templates can only read %s* variables other than WEBHOOK_HMAC_SECRET", name, webhookEnvPrefix)
+	}
+	return os.Getenv(name), nil
+}
+
+// LoadWebhookTemplate parses a webhook template file; it must define "body"
+func LoadWebhookTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(webhookFuncs(&Report{})).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing webhook template: %v", err)
+	}
+	if tmpl.Lookup("body") == nil {
+		return nil, fmt.Errorf("webhook template %s does not define \"body\"", path)
+	}
+	return tmpl, nil
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// render executes the template parts against a report
+func (n *WebhookNotifier) render(report *Report) (*webhookRequest, error) {
+	tmpl, err := n.Template.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(webhookFuncs(report))
+
+	part := func(name string, fallback string) (string, error) {
+		if tmpl.Lookup(name) == nil {
+			return fallback, nil
+		}
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name, report); err != nil {
+			return "", fmt.Errorf("error rendering webhook %s: %v", name, err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	req := &webhookRequest{Headers: http.Header{}}
+	if req.Method, err = part("method", http.MethodPost); err != nil {
+		return nil, err
+	}
+	req.Method = strings.ToUpper(req.Method)
+	if req.URL, err = part("url", n.URL); err != nil {
+		return nil, err
+	}
+	if req.URL == "" {
+		return nil, fmt.Errorf("webhook URL is empty: set --webhook-url or define \"url\" in the template")
+	}
+
+	headers, err := part("headers", "")
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(headers))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid webhook header line %q", line)
+		}
+		req.Headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if req.Headers.Get("Content-Type") == "" {
+		req.Headers.Set("Content-Type", "application/json")
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&body, "body", report); err != nil {
+		return nil, fmt.Errorf("error rendering webhook body: %v", err)
+	}
+	req.Body = body.Bytes()
+
+	if n.HMACSecret != "" {
+		mac := hmac.New(sha256.New, []byte(n.HMACSecret))
+		mac.Write(req.Body)
+		req.Headers.Set(webhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return req, nil
+}
+
+// Preview renders the request exactly as it would be sent
+func (n *WebhookNotifier) Preview(report *Report) ([]byte, error) {
+	req, err := n.render(report)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s\n", req.Method, req.URL)
+	req.Headers.Write(&buf)
+	buf.WriteString("\n")
+	buf.Write(req.Body)
+	return buf.Bytes(), nil
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, report *Report) error {
+	rendered, err := n.render(report)
+	if err != nil {
+		return err
+	}
+
+	backoff := n.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.send(ctx, rendered)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes one attempt and reports whether a failure is worth retrying
+func (n *WebhookNotifier) send(ctx context.Context, rendered *webhookRequest) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, rendered.Method, rendered.URL, bytes.NewReader(rendered.Body))
+	if err != nil {
+		return false, fmt.Errorf("error creating HTTP request: %v", err)
+	}
+	req.Header = rendered.Headers.Clone()
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("error sending HTTP request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return false, nil
+}
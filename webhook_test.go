@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+// loadTestWebhookTemplate writes a template file and loads it like --webhook-template does
+func loadTestWebhookTemplate(t *testing.T, text string) *template.Template {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadWebhookTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+// webhookTestServer answers with the given statuses in turn and records the bodies and signatures it saw
+type webhookTestServer struct {
+	statuses   []int
+	bodies     []string
+	signatures []string
+}
+
+func (s *webhookTestServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(body))
+		s.signatures = append(s.signatures, r.Header.Get(webhookSignatureHeader))
+		status := http.StatusOK
+		if len(s.bodies) <= len(s.statuses) {
+			status = s.statuses[len(s.bodies)-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	fake := &webhookTestServer{statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}}
+	server := fake.start(t)
+	n := &WebhookNotifier{
+		Template: loadTestWebhookTemplate(t, `{{define "body"}}{"quotas":{{len .Quotas}}}{{end}}`),
+		URL:      server.URL,
+		Retries:  3,
+		Backoff:  time.Millisecond,
+		Client:   server.Client(),
+	}
+	if err := n.Notify(context.Background(), newTestReport(2)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(fake.bodies) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(fake.bodies))
+	}
+	for i, body := range fake.bodies {
+		if body != `{"quotas":2}` {
+			t.Errorf("attempt %d body = %q", i, body)
+		}
+	}
+
+	// Giving up after the configured retries returns the last error
+	fake = &webhookTestServer{statuses: []int{500, 500, 500}}
+	server = fake.start(t)
+	n.URL, n.Client, n.Retries = server.URL, server.Client(), 1
+	if err := n.Notify(context.Background(), newTestReport(2)); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Notify error = %v, want the 500 answer", err)
+	}
+	if len(fake.bodies) != 2 {
+		t.Errorf("got %d attempts, want 2", len(fake.bodies))
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	fake := &webhookTestServer{statuses: []int{http.StatusUnauthorized}}
+	server := fake.start(t)
+	n := &WebhookNotifier{
+		Template: loadTestWebhookTemplate(t, `{{define "body"}}{}{{end}}`),
+		URL:      server.URL,
+		Retries:  3,
+		Backoff:  time.Millisecond,
+		Client:   server.Client(),
+	}
+	err := n.Notify(context.Background(), newTestReport(1))
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Notify error = %v, want the 401 answer", err)
+	}
+	if len(fake.bodies) != 1 {
+		t.Errorf("got %d attempts, want 1", len(fake.bodies))
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	fake := &webhookTestServer{}
+	server := fake.start(t)
+	n := &WebhookNotifier{
+		Template:   loadTestWebhookTemplate(t, `{{define "body"}}{"account":"{{.AccountID}}"}{{end}}`),
+		URL:        server.URL,
+		HMACSecret: "s3cret",
+		Client:     server.Client(),
+	}
+	if err := n.Notify(context.Background(), newTestReport(1)); err != nil {
+		t.Fatal(err)
+	}
+	// HMAC-SHA256 of the body below with key "s3cret"
+	const want = "sha256=b294a52513d488db1800e7074ee57736e459758abcba6509ebfc51932ecfea8a"
+	if len(fake.bodies) != 1 || fake.bodies[0] != `{"account":"123456789012"}` {
+		t.Fatalf("bodies = %q", fake.bodies)
+	}
+	if fake.signatures[0] != want {
+		t.Errorf("%s = %q, want %q", webhookSignatureHeader, fake.signatures[0], want)
+	}
+
+	// Without a secret no signature is sent
+	n.HMACSecret = ""
+	if err := n.Notify(context.Background(), newTestReport(1)); err != nil {
+		t.Fatal(err)
+	}
+	if fake.signatures[1] != "" {
+		t.Errorf("unsigned request has %s = %q", webhookSignatureHeader, fake.signatures[1])
+	}
+}
+
+func TestWebhookEnvAllowlist(t *testing.T) {
+	t.Setenv("WEBHOOK_OPS_TOKEN", "ops-token")
+	t.Setenv("WEBHOOK_HMAC_SECRET", "secret")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "aws-secret")
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"WEBHOOK_OPS_TOKEN", "Bearer ops-token", false},
+		{"WEBHOOK_HMAC_SECRET", "", true},
+		{"AWS_SECRET_ACCESS_KEY", "", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "hook.tmpl")
+		text := `{{define "headers"}}Authorization: Bearer {{env "` + tt.name + `"}}{{end}}{{define "body"}}{}{{end}}`
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		tmpl, err := LoadWebhookTemplate(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := &WebhookNotifier{Template: tmpl, URL: "https://ops.example.com/hooks"}
+		rendered, err := n.render(newTestReport(1))
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "is not allowed") {
+				t.Errorf("env %s: error = %v, want it refused", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("env %s: %v", tt.name, err)
+		}
+		if got := rendered.Headers.Get("Authorization"); got != tt.want {
+			t.Errorf("env %s: Authorization = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
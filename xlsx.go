@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSummarySheet = "Summary"
+
+// xlsxSheetName makes a service name a valid, unique worksheet name (at most 31 characters, no []:*?/\)
+func xlsxSheetName(service string, used map[string]bool) string {
+	name := strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "-", "?", "-", "/", "-", `\`, "-").Replace(service)
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	base := name
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		name = string([]rune(base)[:min(len([]rune(base)), 31-len(suffix))]) + suffix
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// xlsxStyles are the cell styles shared by all sheets
+type xlsxStyles struct {
+	header, percent, number, critical, warning int
+}
+
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	var s xlsxStyles
+	var err error
+	if s.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#D9E1F2"}},
+	}); err != nil {
+		return s, err
+	}
+	if s.percent, err = f.NewStyle(&excelize.Style{NumFmt: 10}); err != nil { // 0.00%
+		return s, err
+	}
+	if s.number, err = f.NewStyle(&excelize.Style{NumFmt: 4}); err != nil { // #,##0.00
+		return s, err
+	}
+	if s.critical, err = f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#FFC7CE"}},
+	}); err != nil {
+		return s, err
+	}
+	s.warning, err = f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "#9C5700"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"#FFEB9C"}},
+	})
+	return s, err
+}
+
+// writeXLSXTable writes a header and rows, then freezes the header, adds an autofilter,
+// and formats the utilization column (a fraction shown as a percentage) by severity
+func writeXLSXTable(f *excelize.File, sheet string, styles xlsxStyles, thresholds Thresholds, header []interface{}, rows [][]interface{}, utilizationCol int, numberCols []int) error {
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	if err := f.SetCellStyle(sheet, "A1", lastCol+"1", styles.header); err != nil {
+		return err
+	}
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	if err := f.SetColWidth(sheet, "A", lastCol, 16); err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	lastRow := len(rows) + 1
+	if err := f.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil); err != nil {
+		return err
+	}
+	for _, col := range numberCols {
+		name, _ := excelize.ColumnNumberToName(col)
+		if err := f.SetCellStyle(sheet, name+"2", fmt.Sprintf("%s%d", name, lastRow), styles.number); err != nil {
+			return err
+		}
+	}
+	utilization, _ := excelize.ColumnNumberToName(utilizationCol)
+	cells := fmt.Sprintf("%s2:%s%d", utilization, utilization, lastRow)
+	if err := f.SetCellStyle(sheet, utilization+"2", fmt.Sprintf("%s%d", utilization, lastRow), styles.percent); err != nil {
+		return err
+	}
+	return f.SetConditionalFormat(sheet, cells, []excelize.ConditionalFormatOptions{
+		{Type: "cell", Criteria: ">=", Format: &styles.critical, Value: fmt.Sprint(thresholds.Critical / 100), StopIfTrue: true},
+		{Type: "cell", Criteria: ">=", Format: &styles.warning, Value: fmt.Sprint(thresholds.Warning / 100)},
+	})
+}
+
+// writeXLSX writes a workbook with a summary sheet and one sheet per service; values are
+// numeric cells, utilization is a fraction formatted as a percentage
+func writeXLSX(w io.Writer, report *Report) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return fmt.Errorf("error creating workbook styles: %v", err)
+	}
+	if err := f.SetSheetName("Sheet1", xlsxSummarySheet); err != nil {
+		return err
+	}
+	if err := f.SetDocProps(&excelize.DocProperties{
+		Title:       "AWS Service Quotas " + report.AccountID,
+		Creator:     "awsservicesquotafetcher " + version,
+		Created:     report.GeneratedAt.UTC().Format(time.RFC3339),
+		Description: fmt.Sprintf("Account %s, regions %s", report.AccountID, strings.Join(report.Regions, ",")),
+	}); err != nil {
+		return err
+	}
+
+	services, byService := report.ByService()
+	var summary [][]interface{}
+	used := map[string]bool{strings.ToLower(xlsxSummarySheet): true}
+	for _, service := range services {
+		quotas := byService[service]
+		counts := map[Severity]int{}
+		var rows [][]interface{}
+		for _, q := range quotas {
+			severity := report.Severity(q)
+			counts[severity]++
+			rows = append(rows, []interface{}{
+				report.AccountID, q.Region, q.QuotaCode, q.QuotaName, q.Allocated, q.Used, q.UtilizedPerc / 100, q.Adjustable, q.Global, severity.String(),
+			})
+		}
+		sheet := xlsxSheetName(service, used)
+		summary = append(summary, []interface{}{
+			service, len(quotas), counts[SeverityCritical], counts[SeverityWarning], counts[SeverityOK], quotas[0].UtilizedPerc / 100,
+		})
+
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+		header := []interface{}{"Account", "Region", "Quota Code", "Quota Name", "Allocated", "Used", "Utilization", "Adjustable", "Global", "Severity"}
+		if err := writeXLSXTable(f, sheet, styles, report.Thresholds, header, rows, 7, []int{5, 6}); err != nil {
+			return fmt.Errorf("error writing sheet %s: %v", sheet, err)
+		}
+		if err := f.SetColWidth(sheet, "D", "D", 48); err != nil {
+			return err
+		}
+	}
+
+	header := []interface{}{"Service", "Quotas", "Critical", "Warning", "OK", "Highest Utilization"}
+	if err := writeXLSXTable(f, xlsxSummarySheet, styles, report.Thresholds, header, summary, 6, nil); err != nil {
+		return fmt.Errorf("error writing summary sheet: %v", err)
+	}
+
+	// Run metadata below the summary table
+	metadata := [][]interface{}{
+		{"Account", report.AccountID},
+		{"Regions", strings.Join(report.Regions, ", ")},
+		{"Generated At", report.GeneratedAt.UTC().Format(time.RFC3339)},
+		{"Warning Threshold", report.Thresholds.Warning / 100},
+		{"Critical Threshold", report.Thresholds.Critical / 100},
+		{"Tool Version", version},
+	}
+	start := len(summary) + 3
+	for i, row := range metadata {
+		cell, _ := excelize.CoordinatesToCellName(1, start+i)
+		if err := f.SetSheetRow(xlsxSummarySheet, cell, &row); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(xlsxSummarySheet, fmt.Sprintf("A%d", start), fmt.Sprintf("A%d", start+len(metadata)-1), styles.header); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(xlsxSummarySheet, fmt.Sprintf("B%d", start+3), fmt.Sprintf("B%d", start+4), styles.percent); err != nil {
+		return err
+	}
+
+	f.SetActiveSheet(0)
+	_, err = f.WriteTo(w)
+	return err
+}
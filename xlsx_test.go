@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXSheetName(t *testing.T) {
+	used := map[string]bool{strings.ToLower(xlsxSummarySheet): true}
+	tests := []struct {
+		service, want string
+	}{
+		{"Amazon Elastic Compute Cloud (Amazon EC2)", "Amazon Elastic Compute Cloud (A"},
+		{"Amazon Elastic Compute Cloud (Amazon EC2) Dedicated", "Amazon Elastic Compute Clou (2)"},
+		{"summary", "summary (2)"},
+		{"AWS [Beta]: a/b\\c*?", "AWS (Beta)- a-b-c--"},
+		{"aws (beta)- a-b-c--", "aws (beta)- a-b-c-- (2)"},
+	}
+	for _, tt := range tests {
+		got := xlsxSheetName(tt.service, used)
+		if got != tt.want {
+			t.Errorf("xlsxSheetName(%q) = %q, want %q", tt.service, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n > 31 {
+			t.Errorf("xlsxSheetName(%q) is %d characters", tt.service, n)
+		}
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	report := newTestReport(9)
+	long := "Amazon Elastic Compute Cloud (Amazon EC2) With An Even Longer Name"
+	report.Quotas[0].ServiceName = long
+	report.Quotas[1].ServiceName = long + " Two"
+	report.Quotas[2].ServiceName = "summary"
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	services, byService := report.ByService()
+	sheets := f.GetSheetList()
+	if len(sheets) != len(services)+1 || sheets[0] != xlsxSummarySheet {
+		t.Fatalf("sheets = %q, want Summary plus %d services", sheets, len(services))
+	}
+	seen := map[string]bool{}
+	for _, sheet := range sheets {
+		if n := utf8.RuneCountInString(sheet); n > 31 {
+			t.Errorf("sheet %q is %d characters", sheet, n)
+		}
+		if seen[strings.ToLower(sheet)] {
+			t.Errorf("sheet name %q is not unique", sheet)
+		}
+		seen[strings.ToLower(sheet)] = true
+	}
+
+	// Sheets follow the service order; check Allocated, Used and Utilization are numbers
+	for i, service := range services {
+		sheet := sheets[i+1]
+		quotas := byService[service]
+		for row, q := range quotas {
+			for _, c := range []struct {
+				col  string
+				want float64
+			}{{"E", q.Allocated}, {"F", q.Used}, {"G", q.UtilizedPerc / 100}} {
+				cell := c.col + strconv.Itoa(row+2)
+				typ, err := f.GetCellType(sheet, cell)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if typ != excelize.CellTypeUnset && typ != excelize.CellTypeNumber {
+					t.Errorf("%s!%s has type %v, want a number", sheet, cell, typ)
+				}
+				raw, err := f.GetCellValue(sheet, cell, excelize.Options{RawCellValue: true})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, err := strconv.ParseFloat(raw, 64); err != nil || got != c.want {
+					t.Errorf("%s!%s = %q, want %v", sheet, cell, raw, c.want)
+				}
+			}
+		}
+	}
+}